@@ -0,0 +1,110 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var (
+	bundleCmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "create/import a bundle to install python without network.",
+		Long: `create/import a bundle to install python without network.
+
+a bundle is a tar file which contains the version cache and verified installers.
+create it on a machine with network, import it on the other, then use "--offline":
+  pim bundle create --versions 3.11,3.12 out.tar
+  pim bundle import out.tar
+  pim --offline install 3.12
+
+installers are for the platform, provider and distribution of the creator.
+imported installers are not trusted by the bundle itself. they are verified by
+their sigstore bundles (cosign is required), or by the published hash when online.`,
+	}
+
+	bundleCreateCmd = &cobra.Command{
+		Use:   "create --versions <versions> <bundle.tar>",
+		Short: "create a bundle with the newest installers of the versions.",
+		Long: `create a bundle with the newest installers of the versions.
+
+versions are comma separated, same as .python-version. (e.g. "3.11,3.12.7,3.13t")
+installers are downloaded and verified if not cached yet.`,
+
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versions, err := cmd.Flags().GetStringSlice("versions")
+			if err != nil {
+				return err
+			}
+			if len(versions) == 0 {
+				return &lib.UsageError{Err: errors.New("--versions is required")}
+			}
+
+			specs := make([]lib.Specifier, 0, len(versions))
+			for _, version := range versions {
+				spec, err := lib.ParsePinnedVersion(version)
+				if err != nil {
+					return &lib.UsageError{Err: err}
+				}
+				specs = append(specs, spec)
+			}
+			return lib.CreateBundle(cmd.Context(), config, provider, specs, args[0])
+		},
+	}
+
+	bundleImportCmd = &cobra.Command{
+		Use:   "import <bundle.tar>",
+		Short: "import the version cache and installers in a bundle.",
+		Long: `import the version cache and installers in a bundle.
+
+installers with sigstore bundles are verified by cosign on import.
+others are verified by the published hash when used online, and can not be used offline.
+with --trust, sha256 in the bundle manifest is trusted instead. use it only for bundles you created.`,
+
+		Args: cobra.ExactArgs(1),
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trust, err := cmd.Flags().GetBool("trust")
+			if err != nil {
+				return err
+			}
+			return lib.ImportBundle(cmd.Context(), config, args[0], trust)
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleCmd.AddCommand(bundleCreateCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+
+	bundleCreateCmd.Flags().StringSlice("versions", nil, "versions to bundle (e.g. 3.11,3.12)")
+	bundleImportCmd.Flags().Bool("trust", false, "trust installers without signature by sha256 in the bundle manifest")
+}
@@ -33,14 +33,67 @@ var (
 	cleanCmd = &cobra.Command{
 		Use:   "clean",
 		Short: "clean cache, installer, etc...",
-		Long:  "clean cache, installer, etc...",
+		Long: `clean cache, installer, etc...
+
+without flags, the version cache and all cached installers are removed.
+--older-than, --keep-installed and --max-size select installers to remove, and can be combined.
+e.g. "pim clean --older-than 30d --keep-installed --max-size 2GB"
+
+installers are also removed automatically after install, if CleanOlderThan or CleanMaxSize is set in config.
+(CleanKeepInstalled = true keeps installers of installed python)`,
+
+		Args: cobra.NoArgs,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return lib.CleanCache()
+			options := lib.CleanOptions{MaxSize: -1}
+			var err error
+			if options.VersionsCache, err = cmd.Flags().GetBool("versions-cache"); err != nil {
+				return err
+			}
+			if options.Installers, err = cmd.Flags().GetBool("installers"); err != nil {
+				return err
+			}
+			if options.KeepInstalled, err = cmd.Flags().GetBool("keep-installed"); err != nil {
+				return err
+			}
+			if options.DryRun, err = cmd.Flags().GetBool("dry-run"); err != nil {
+				return err
+			}
+			if olderThan, err := cmd.Flags().GetString("older-than"); err != nil {
+				return err
+			} else if olderThan != "" {
+				if options.OlderThan, err = lib.ParseAge(olderThan); err != nil {
+					return &lib.UsageError{Err: err}
+				}
+			}
+			if maxSize, err := cmd.Flags().GetString("max-size"); err != nil {
+				return err
+			} else if maxSize != "" {
+				if options.MaxSize, err = lib.ParseSize(maxSize); err != nil {
+					return &lib.UsageError{Err: err}
+				}
+			}
+
+			// installer filters imply --installers. nothing selected means everything.
+			if options.OlderThan > 0 || options.KeepInstalled || options.MaxSize >= 0 {
+				options.Installers = true
+			}
+			if !options.VersionsCache && !options.Installers {
+				options.VersionsCache = true
+				options.Installers = true
+			}
+			return lib.Clean(cmd.Context(), config, options)
 		},
 	}
 )
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
+
+	cleanCmd.Flags().Bool("versions-cache", false, "remove the version cache")
+	cleanCmd.Flags().Bool("installers", false, "remove cached installers")
+	cleanCmd.Flags().String("older-than", "", `remove installers not used for longer than this (e.g. "30d", "2w", "12h")`)
+	cleanCmd.Flags().Bool("keep-installed", false, "keep installers needed to uninstall installed python")
+	cleanCmd.Flags().String("max-size", "", `remove least recently used installers until the total size is within this (e.g. "2GB")`)
+	cleanCmd.Flags().Bool("dry-run", false, "only show what would be removed")
 }
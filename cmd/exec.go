@@ -0,0 +1,67 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var execCmd = &cobra.Command{
+	Use:     "exec <version> [--] [args...]",
+	Aliases: []string{"run"},
+	Short:   "run installed python of the version.",
+	Long: `run installed python of the version.
+
+the newest installed python matching the version (e.g. 3.11, 3.13t, >=3.10) is used.
+arguments after the version are passed to python as is.
+stdin, stdout, stderr and exit code are passed through.
+
+example: pim exec 3.11 -- script.py args...`,
+
+	Args: cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pythonArgs := args[1:]
+		if len(pythonArgs) > 0 && pythonArgs[0] == "--" {
+			pythonArgs = pythonArgs[1:]
+		}
+
+		code, err := lib.ExecPython(config, args[0], pythonArgs)
+		if err != nil {
+			return err
+		}
+		os.Exit(code)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+	// flags after the version are arguments of python.
+	execCmd.Flags().SetInterspersed(false)
+}
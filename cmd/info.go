@@ -0,0 +1,79 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <version>",
+	Short: "show information of installed python.",
+	Long: `show information of installed python.
+
+the newest installed python matching the version (e.g. 3.12, 3.13t, >=3.10) is used.
+install directory, scope (windows: HKLM or HKCU), arch, company and cached installer are shown.`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installation, err := findInstallation(args[0])
+		if err != nil {
+			return err
+		}
+
+		scope := installation.Scope
+		if installation.Registry != "" {
+			scope = fmt.Sprintf("%s (%s)", scope, installation.Registry)
+		}
+		company := installation.Company
+		if company == "" {
+			company = "unknown"
+		}
+		installer, ok := installation.CachedInstaller(config)
+		if !ok {
+			installer = "not cached"
+		}
+
+		out := lib.MessageOut()
+		fmt.Fprintln(out, installation.DisplayName)
+		fmt.Fprintf(out, "  version:      %s\n", installation.Version.String())
+		fmt.Fprintf(out, "  executable:   %s\n", installation.ExecutablePath)
+		fmt.Fprintf(out, "  directory:    %s\n", installation.DirectoryPath)
+		fmt.Fprintf(out, "  scope:        %s\n", scope)
+		fmt.Fprintf(out, "  arch:         %s\n", installation.Arch)
+		fmt.Fprintf(out, "  company:      %s\n", company)
+		fmt.Fprintf(out, "  distribution: %s\n", installation.Distribution)
+		fmt.Fprintf(out, "  installer:    %s\n", installer)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
@@ -27,6 +27,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/hawk-tomy/pim/lib"
 	"github.com/spf13/cobra"
@@ -34,60 +35,88 @@ import (
 
 var (
 	installCmd = &cobra.Command{
-		Use:   "install",
+		Use:   "install [version]",
 		Short: "install python",
-		Long:  "install python",
+		Long: `install python
 
-		Args: cobra.ExactArgs(1),
+version is exact version (e.g. "3.12.1"), or PEP 440 version specifier (e.g. ">=3.11,<3.13", "~=3.12.2", "3.12.*", "!=3.12.5").
+if specifier is given, the newest matching version is installed.
+if version is not given, the version in the nearest .python-version is installed.
+"t" suffix installs free-threaded (no-GIL) build, available since 3.13. (e.g. "3.13t", "3.13.1t", ">=3.13t")`,
 
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) != 1 {
-				fmt.Println("1st argument must be version.")
-			}
-
-			version, err := lib.NewVersion(args[0])
-			if err != nil {
-				return err
-			}
+		Args: cobra.MaximumNArgs(1),
 
+		RunE: func(cmd *cobra.Command, args []string) error {
 			needLatest, err := cmd.Flags().GetBool("latest")
 			if err != nil {
 				return err
 			}
 
-			versionInfo := version.String()
-			if needLatest {
-				versionInfo = fmt.Sprintf("%d.%d.x (detect latest)", version.Major, version.Minor)
+			var spec lib.Specifier
+			var versionInfo string
+			if len(args) == 0 {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				var path string
+				if spec, path, err = lib.FindPinnedVersion(cwd); err != nil {
+					if errors.Is(err, lib.ErrPythonVersionFileNotFound) {
+						return fmt.Errorf("1st argument must be version: %w", err)
+					}
+					return err
+				}
+				versionInfo = fmt.Sprintf("%s (set by %s)", spec.String(), path)
+			} else if version, err := lib.NewVersion(args[0]); err == nil {
+				spec = lib.NewExactSpecifier(version)
+				versionInfo = version.String()
+				if needLatest {
+					spec = lib.NewMinorSpecifier(version)
+					versionInfo = fmt.Sprintf("%d.%d.x (detect latest)", version.Major, version.Minor)
+				}
+				if version.FreeThreaded {
+					versionInfo += " (free-threaded)"
+				}
+			} else {
+				if spec, err = lib.ParseSpecifier(args[0]); err != nil {
+					return err
+				}
+				versionInfo = fmt.Sprintf("%s (detect latest)", spec.String())
 			}
 
-			fmt.Printf("install options\n")
-			fmt.Printf("  version: %s\n", versionInfo)
-			fmt.Printf("  for all user: %s\n", lib.YesOrNo(config.ForAllUser))
+			out := lib.MessageOut()
+			fmt.Fprintf(out, "install options\n")
+			fmt.Fprintf(out, "  version: %s\n", versionInfo)
+			if config.Distribution != "" {
+				fmt.Fprintf(out, "  distribution: %s\n", config.Distribution)
+			}
+			fmt.Fprintf(out, "  for all user: %s\n", lib.YesOrNo(config.ForAllUser))
 			if config.TargetDirectory != "" {
-				fmt.Printf("  install path: %s\n", config.TargetDirectory)
+				fmt.Fprintf(out, "  install path: %s\n", config.TargetDirectory)
 			} else if config.ForAllUser {
-				fmt.Printf("  install path: default(all user)\n")
+				fmt.Fprintf(out, "  install path: default(all user)\n")
 			} else {
-				fmt.Printf("  install path: default(only you)\n")
+				fmt.Fprintf(out, "  install path: default(only you)\n")
 			}
 
-			if lib.Confirm("continue? [Y/n]: ") {
-				fmt.Printf("installing...\n")
-				err = lib.InstallPython(config, version, needLatest)
+			doc := lib.NewInstallDocument(config, spec)
+			if lib.Confirm(cmd.Context(), "continue? [Y/n]: ") {
+				fmt.Fprintf(out, "installing...\n")
+				installed, err := lib.InstallPython(cmd.Context(), config, provider, spec)
 				if err != nil {
 					var sErr *lib.StatusError
 					if errors.As(err, &sErr) {
-						fmt.Printf("failed to download installer. version: %s, status: %d\n", version.String(), sErr.Status)
-						return nil
+						return fmt.Errorf("failed to download installer. version: %s: %w", versionInfo, err)
 					}
 					return err
 				}
-
+				doc.Installed = installed.String()
+				doc.Canceled = false
 			} else {
-				fmt.Println("canceled.")
+				fmt.Fprintln(out, "canceled.")
 			}
 
-			return nil
+			return lib.WriteDocument(doc)
 		},
 	}
 )
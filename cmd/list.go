@@ -0,0 +1,91 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list installed python, or available versions with --remote.",
+	Long: `list installed python, or available versions with --remote.
+
+version, distribution and executable path of each installed python are shown.
+use "--output json" or "--output yaml" to get full information.
+
+with --remote, versions known by the provider are shown for each minor version.
+pre-releases, versions without installer and installed versions are marked.
+by default, the newest 5 versions of supported minor versions are shown.
+versions are read from cache if it is fresh. with --offline, expired cache is also used.`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remote, err := cmd.Flags().GetBool("remote")
+		if err != nil {
+			return err
+		}
+
+		var options lib.RemoteListOptions
+		if options.Minor, err = cmd.Flags().GetString("minor"); err != nil {
+			return err
+		}
+		if options.PreRelease, err = cmd.Flags().GetBool("pre"); err != nil {
+			return err
+		}
+		if options.All, err = cmd.Flags().GetBool("all"); err != nil {
+			return err
+		}
+		company, err := cmd.Flags().GetString("company")
+		if err != nil {
+			return err
+		}
+
+		if !remote {
+			if options.Minor != "" || options.PreRelease || options.All {
+				return &lib.UsageError{Err: errors.New("--minor, --pre and --all are available only with --remote")}
+			}
+			return lib.ListCommand(config, company)
+		}
+		if company != "" {
+			return &lib.UsageError{Err: errors.New("--company is not available with --remote")}
+		}
+		return lib.ListRemoteCommand(cmd.Context(), config, provider, options)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().BoolP("remote", "r", false, "list versions available to install")
+	listCmd.Flags().String("minor", "", "show all versions of the minor version (e.g. 3.12)")
+	listCmd.Flags().Bool("pre", false, "show pre-releases")
+	listCmd.Flags().Bool("all", false, "show all versions of all minor versions")
+	listCmd.Flags().String("company", "", `show only installed python of the PEP 514 company (e.g. "PythonCore")`)
+}
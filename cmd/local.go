@@ -0,0 +1,77 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var localCmd = &cobra.Command{
+	Use:   "local [version]",
+	Short: "pin python version of current directory.",
+	Long: `pin python version of current directory.
+
+write version into .python-version in current directory. (same as pyenv)
+version is "Major.Minor" (latest of the minor version), exact version, or PEP 440 version specifier.
+if version is not given, show the version in .python-version of current directory.`,
+
+	Args: cobra.MaximumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			version, err := lib.ReadPythonVersionFile(filepath.Join(cwd, lib.PythonVersionFileName))
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(lib.MessageOut(), "%s is not found in current directory.\n", lib.PythonVersionFileName)
+				return nil
+			} else if err != nil {
+				return err
+			}
+			fmt.Fprintln(lib.MessageOut(), version)
+			return nil
+		}
+
+		path, err := lib.WritePythonVersionFile(cwd, args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(lib.MessageOut(), "wrote %s: %s\n", path, args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(localCmd)
+}
@@ -25,8 +25,13 @@ SOFTWARE.
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/hawk-tomy/pim/lib"
 	"github.com/spf13/cobra"
@@ -36,12 +41,17 @@ import (
 type flagConfigT struct {
 	AllowPreRelease bool
 	ForAllUser      bool
+	Provider        string
+	Distribution    string
+	Offline         bool
 }
 
 var (
-	cfgFile    string
-	config     lib.Config
-	flagConfig flagConfigT
+	cfgFile      string
+	outputFormat string
+	config       lib.Config
+	flagConfig   flagConfigT
+	provider     lib.Provider
 )
 
 var rootCmd = &cobra.Command{
@@ -53,16 +63,35 @@ Install/Update python with select/latest version.
 You can install, update, show installed version.
 
 Now, only support python/cpython. (PR is welcome!)`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := lib.SetOutputFormat(outputFormat); err != nil {
+			return err
+		}
 		if cmd.Flags().Changed("pre-release") {
 			config.AllowPreRelease = flagConfig.AllowPreRelease
 		}
 		if cmd.Flags().Changed("all-user") {
 			config.ForAllUser = flagConfig.ForAllUser
 		}
+		if cmd.Flags().Changed("provider") {
+			config.Provider = flagConfig.Provider
+		}
+		if cmd.Flags().Changed("distribution") {
+			config.Distribution = flagConfig.Distribution
+		}
+		if cmd.Flags().Changed("offline") {
+			config.Offline = flagConfig.Offline
+		}
+		if config.Offline && lib.RefreshCache {
+			return &lib.UsageError{Err: errors.New("--refresh can not be used with --offline")}
+		}
 		if lib.WithVerbose > 0 {
-			fmt.Printf("config: %+v\n", config)
+			fmt.Fprintf(lib.MessageOut(), "config: %+v\n", config)
 		}
+
+		var err error
+		provider, err = lib.NewProvider(config)
+		return err
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) == 0 {
@@ -70,12 +99,23 @@ Now, only support python/cpython. (PR is welcome!)`,
 		}
 		return nil
 	},
-	SilenceUsage: true,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	// Ctrl-C cancels the context, and running downloads and builds are stopped.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		// output format is not set yet if parsing flags is failed.
+		if lib.SetOutputFormat(outputFormat) == nil && lib.IsStructuredOutput() {
+			cobra.CheckErr(lib.WriteDocument(lib.NewErrorDocument(err)))
+		} else {
+			rootCmd.PrintErrln(rootCmd.ErrPrefix(), err.Error())
+		}
+		os.Exit(lib.ExitCode(err))
 	}
 }
 
@@ -90,6 +130,23 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&flagConfig.ForAllUser, "all-user", "a", false, "install for all user")
 	cobra.CheckErr(viper.BindPFlag("ForAllUser", rootCmd.PersistentFlags().Lookup("all-user")))
 
+	rootCmd.PersistentFlags().StringVar(&flagConfig.Provider, "provider", "", fmt.Sprintf("python provider. (available: %s)", strings.Join(lib.ProviderNames(), ", ")))
+	cobra.CheckErr(viper.BindPFlag("Provider", rootCmd.PersistentFlags().Lookup("provider")))
+
+	rootCmd.PersistentFlags().StringVar(&flagConfig.Distribution, "distribution", "", `how to install python. "native" or "standalone".
+
+native: official installer on windows, build from source code on linux.
+standalone: prebuilt python-build-standalone archive. (no admin rights or compiler is required)
+`)
+	cobra.CheckErr(viper.BindPFlag("Distribution", rootCmd.PersistentFlags().Lookup("distribution")))
+
+	rootCmd.PersistentFlags().BoolVar(&flagConfig.Offline, "offline", false, `never access network. use only the version cache and cached installers.
+import them by "pim bundle import" beforehand.
+`)
+	cobra.CheckErr(viper.BindPFlag("Offline", rootCmd.PersistentFlags().Lookup("offline")))
+
+	rootCmd.PersistentFlags().BoolVar(&lib.RefreshCache, "refresh", false, "fetch versions again even if the version cache is not expired. (see CacheTTL in config)")
+
 	rootCmd.PersistentFlags().StringP("target-directory", "t", "", "install target directory")
 	cobra.CheckErr(viper.BindPFlag("TargetDirectory", rootCmd.PersistentFlags().Lookup("target-directory")))
 
@@ -108,6 +165,13 @@ e.g. enable 'CompileAll' =>  "-o CompileAll=1" or "--additional-option=CompileAl
 
 	rootCmd.PersistentFlags().BoolVarP(&lib.SkipConfirm, "force", "f", false, "skip confirmation")
 	rootCmd.PersistentFlags().CountVarP(&lib.WithVerbose, "verbose", "v", "verbose output. (experimental)")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", lib.OutputText, `output format. "text", "json" or "yaml".
+json and yaml are structured documents described by "pim schema".
+`)
+
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &lib.UsageError{Err: err}
+	})
 }
 
 func initConfig() {
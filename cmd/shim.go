@@ -0,0 +1,103 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var (
+	shimCmd = &cobra.Command{
+		Use:   "shim",
+		Short: "manage shims which dispatch python/pip to pinned version.",
+		Long: `manage shims which dispatch python/pip to pinned version.
+
+shims (python, python3, pip, pip3, pythonX.Y) are placed in the pim bin directory.
+add the directory to $PATH, then python version is selected by (in order):
+  1. PIM_PYTHON environment variable
+  2. the nearest .python-version (pim local)
+  3. DefaultPython in config`,
+	}
+
+	shimInstallCmd = &cobra.Command{
+		Use:     "install",
+		Aliases: []string{"rehash"},
+		Short:   "generate shims. run again after installing new minor version.",
+		Long:    "generate shims. run again after installing new minor version.",
+
+		Args: cobra.NoArgs,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := lib.InstallShims(config)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(lib.MessageOut(), "generated shims in %s:\n", lib.ShimDir())
+			fmt.Fprintf(lib.MessageOut(), "  %s\n", strings.Join(names, ", "))
+
+			inPath := false
+			for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+				if filepath.Clean(dir) == filepath.Clean(lib.ShimDir()) {
+					inPath = true
+				}
+			}
+			if !inPath {
+				fmt.Fprintf(lib.MessageOut(), "add %s to $PATH (before other python) to use shims.\n", lib.ShimDir())
+			}
+			return nil
+		},
+	}
+
+	shimExecCmd = &cobra.Command{
+		Use:    "exec <name> [args...]",
+		Short:  "run python for the shim. (called by shims)",
+		Long:   "run python for the shim. (called by shims)",
+		Hidden: true,
+
+		Args:               cobra.MinimumNArgs(1),
+		DisableFlagParsing: true,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, err := lib.ExecShim(config, args[0], args[1:])
+			if err != nil {
+				return err
+			}
+			os.Exit(code)
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(shimCmd)
+	shimCmd.AddCommand(shimInstallCmd)
+	shimCmd.AddCommand(shimExecCmd)
+}
@@ -35,16 +35,23 @@ var statusCmd = &cobra.Command{
 	Short: "Check installed python versions and latest python versions.",
 	Long: `Check installed python versions and latest python versions.
 
-	List up installed python versions and show updatable python versions.
+	List up installed python versions grouped by company, and show updatable python versions.
 	Check installed python:
-		windows: registry(PEP 514), $PATH.
-		other: $PATH.
+		windows: registry(PEP 514, all companies), $PATH.
+		linux: installed by pim, $PATH.
+	only PythonCore and python-build-standalone are updated and uninstalled by pim.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return lib.StatusCommand(config)
+		company, err := cmd.Flags().GetString("company")
+		if err != nil {
+			return err
+		}
+		return lib.StatusCommand(cmd.Context(), config, provider, company)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().String("company", "", `show only the PEP 514 company (e.g. "PythonCore", "ContinuumAnalytics")`)
 }
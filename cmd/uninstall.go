@@ -40,7 +40,7 @@ var uninstallCmd = &cobra.Command{
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			fmt.Println("1st argument must be version.")
+			fmt.Fprintln(lib.MessageOut(), "1st argument must be version.")
 		}
 		version, err := lib.NewVersion(args[0])
 		if err != nil {
@@ -51,10 +51,20 @@ var uninstallCmd = &cobra.Command{
 			return fmt.Errorf("version must be only 'Major.Minor'")
 		}
 
-		return lib.UninstallPython(config, version)
+		var filter lib.InstallationFilter
+		if filter.Arch, err = cmd.Flags().GetString("arch"); err != nil {
+			return err
+		}
+		if filter.Scope, err = cmd.Flags().GetString("scope"); err != nil {
+			return err
+		}
+		return lib.UninstallPython(cmd.Context(), config, provider, version, filter)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(uninstallCmd)
+
+	uninstallCmd.Flags().String("arch", "", `arch of python to uninstall, if installed side by side (e.g. "386")`)
+	uninstallCmd.Flags().String("scope", "", `scope of python to uninstall, if installed side by side ("all-user" or "per-user")`)
 }
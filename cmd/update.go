@@ -25,6 +25,7 @@ SOFTWARE.
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/hawk-tomy/pim/lib"
@@ -46,24 +47,39 @@ var updateCmd = &cobra.Command{
 		}
 		if !isAllVer {
 			if len(args) != 1 {
-				fmt.Println("accept only 1 argument.")
+				fmt.Fprintln(lib.MessageOut(), "accept only 1 argument.")
 				return nil
 			}
 		} else {
 			if len(args) != 0 {
-				fmt.Println("accept no argument.")
+				fmt.Fprintln(lib.MessageOut(), "accept no argument.")
 				return nil
 			}
 		}
 
+		var filter lib.InstallationFilter
+		if filter.Arch, err = cmd.Flags().GetString("arch"); err != nil {
+			return err
+		}
+		if filter.Scope, err = cmd.Flags().GetString("scope"); err != nil {
+			return err
+		}
+
 		if isAllVer {
-			return lib.UpdateAll(config)
+			if filter != (lib.InstallationFilter{}) {
+				return &lib.UsageError{Err: errors.New("--arch and --scope are not available with --all")}
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			return lib.UpdateAll(cmd.Context(), config, provider, dryRun)
 		} else {
 			version, err := lib.NewVersion(args[0])
 			if err != nil {
 				return err
 			}
-			return lib.UpdateLatest(config, version)
+			return lib.UpdateLatest(cmd.Context(), config, provider, version, filter)
 		}
 
 	},
@@ -73,4 +89,7 @@ func init() {
 	rootCmd.AddCommand(updateCmd)
 
 	updateCmd.Flags().BoolP("all", "A", false, "update all updatable python")
+	updateCmd.Flags().Bool("dry-run", false, "show update plan of --all without updating")
+	updateCmd.Flags().String("arch", "", `update only python of the arch, if installed side by side (e.g. "386")`)
+	updateCmd.Flags().String("scope", "", `update only python of the scope, if installed side by side ("all-user" or "per-user")`)
 }
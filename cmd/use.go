@@ -0,0 +1,71 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var useCmd = &cobra.Command{
+	Use:   "use",
+	Short: "show python version pinned for current directory.",
+	Long: `show python version pinned for current directory.
+
+find .python-version from current directory to root directory, and show the version and installed python which matches it.`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+
+		spec, path, err := lib.FindPinnedVersion(cwd)
+		if errors.Is(err, lib.ErrPythonVersionFileNotFound) {
+			fmt.Fprintf(lib.MessageOut(), "%s is not found. pin version with `pim local <version>`.\n", lib.PythonVersionFileName)
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(lib.MessageOut(), "%s (set by %s)\n", spec.String(), path)
+		if installation, err := lib.FindInstallation(config, spec); err == nil {
+			fmt.Fprintf(lib.MessageOut(), "installed: %s (%s)\n", installation.Version.String(), installation.ExecutablePath)
+		} else {
+			fmt.Fprintln(lib.MessageOut(), "not installed. install with `pim install`.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(useCmd)
+}
@@ -0,0 +1,63 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/hawk-tomy/pim/lib"
+	"github.com/spf13/cobra"
+)
+
+var whichCmd = &cobra.Command{
+	Use:   "which <version>",
+	Short: "show executable path of installed python.",
+	Long: `show executable path of installed python.
+
+the newest installed python matching the version (e.g. 3.12, 3.13t, >=3.10) is used.`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installation, err := findInstallation(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(lib.MessageOut(), installation.ExecutablePath)
+		return nil
+	},
+}
+
+func findInstallation(version string) (lib.Installation, error) {
+	spec, err := lib.ParsePinnedVersion(version)
+	if err != nil {
+		return lib.Installation{}, err
+	}
+	return lib.FindInstallation(config, spec)
+}
+
+func init() {
+	rootCmd.AddCommand(whichCmd)
+}
@@ -0,0 +1,211 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// openArchive returns decompressed tar stream of path.
+// supported: .tar.gz, .tgz, .tar.zst (zstd command is required)
+func openArchive(path string) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			deferErrCheck(f.Close)
+			return nil, err
+		}
+		return &gzipFile{gz, f}, nil
+	case strings.HasSuffix(path, ".tar.zst"):
+		cmd := exec.Command("zstd", "-dc", path)
+		out, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("zstd command is required to extract %s: %w", path, err)
+		}
+		return &commandOutput{out, cmd}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive: %s", path)
+	}
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	if err := g.Reader.Close(); err != nil {
+		return err
+	}
+	return g.file.Close()
+}
+
+type commandOutput struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *commandOutput) Close() error {
+	if err := c.ReadCloser.Close(); err != nil {
+		return err
+	}
+	return c.cmd.Wait()
+}
+
+// checkNoSymlink returns error if target or its parents under dest is a symlink.
+// writing through symlink may write outside of dest.
+func checkNoSymlink(dest string, target string) error {
+	rel, err := filepath.Rel(dest, target)
+	if err != nil {
+		return err
+	}
+	current := dest
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("can not write through symlink in archive: %s", current)
+		}
+	}
+	return nil
+}
+
+// linkTarget returns the path linkname points to. linkname is relative to base, and must be in dest.
+func linkTarget(dest string, base string, linkname string) (string, error) {
+	if filepath.IsAbs(linkname) || strings.HasPrefix(linkname, "/") || strings.HasPrefix(linkname, `\`) {
+		return "", fmt.Errorf("absolute link in archive: %s", linkname)
+	}
+	resolved := filepath.Join(base, linkname)
+	if !isUnder(resolved, dest) {
+		return "", fmt.Errorf("link to outside of archive: %s", linkname)
+	}
+	return resolved, nil
+}
+
+// extractArchive extracts the tar archive at path into dest.
+// only entries under stripPrefix are extracted, and stripPrefix is removed from their names.
+func extractArchive(path string, dest string, stripPrefix string) error {
+	r, err := openArchive(path)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(r.Close)
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimPrefix(header.Name, "./")
+		if !strings.HasPrefix(name, stripPrefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, stripPrefix)
+		if name == "" {
+			continue
+		}
+
+		target := filepath.Join(dest, name)
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path in archive: %s", header.Name)
+		}
+		if err := checkNoSymlink(dest, target); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			deferErrCheck(out.Close)
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			// symlink is relative to its directory.
+			if _, err := linkTarget(dest, filepath.Dir(target), header.Linkname); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			linkname := strings.TrimPrefix(header.Linkname, "./")
+			if !strings.HasPrefix(linkname, stripPrefix) {
+				return fmt.Errorf("link to outside of archive: %s", header.Linkname)
+			}
+			// hard link is relative to the root of archive.
+			source, err := linkTarget(dest, dest, strings.TrimPrefix(linkname, stripPrefix))
+			if err != nil {
+				return err
+			}
+			if err := checkNoSymlink(dest, source); err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,190 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func tarFile(name string, body string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeReg, body: body}
+}
+func tarDir(name string) tarEntry { return tarEntry{name: name, typeflag: tar.TypeDir} }
+func tarSymlink(name string, target string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeSymlink, linkname: target}
+}
+func tarHardlink(name string, target string) tarEntry {
+	return tarEntry{name: name, typeflag: tar.TypeLink, linkname: target}
+}
+
+// writeTarGz writes entries as .tar.gz archive at path.
+func writeTarGz(t *testing.T, path string, entries ...tarEntry) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deferErrCheck(f.Close)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Typeflag: entry.typeflag, Linkname: entry.linkname, Mode: 0644, Size: int64(len(entry.body))}
+		if entry.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestArchive returns .tar.gz archive of entries.
+func writeTestArchive(t *testing.T, entries ...tarEntry) []byte {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "archive.tar.gz")
+	writeTarGz(t, path, entries...)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestExtractArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    map[string]string // path in dest -> content
+		wantErr string
+		// creating symlink requires privilege on windows.
+		symlink bool
+	}{
+		{
+			name:    "strip prefix",
+			entries: []tarEntry{tarDir("python/"), tarDir("python/bin/"), tarFile("python/bin/python3", "py"), tarFile("./python/README", "readme"), tarFile("other/file", "skipped")},
+			want:    map[string]string{"bin/python3": "py", "README": "readme"},
+		},
+		{
+			name:    "relative symlink",
+			entries: []tarEntry{tarFile("python/bin/python3.12", "py"), tarSymlink("python/bin/python3", "python3.12")},
+			want:    map[string]string{"bin/python3": "py"},
+			symlink: true,
+		},
+		{
+			name:    "hard link",
+			entries: []tarEntry{tarFile("python/bin/python3.12", "py"), tarHardlink("python/bin/python3", "python/bin/python3.12")},
+			want:    map[string]string{"bin/python3": "py"},
+		},
+		{
+			name:    "path traversal",
+			entries: []tarEntry{tarFile("python/../../evil", "x")},
+			wantErr: "invalid file path",
+		},
+		{
+			name:    "absolute symlink",
+			entries: []tarEntry{tarSymlink("python/passwd", "/etc/passwd")},
+			wantErr: "absolute link",
+		},
+		{
+			name:    "symlink to outside",
+			entries: []tarEntry{tarSymlink("python/lib/up", "../../outside")},
+			wantErr: "link to outside",
+		},
+		{
+			name:    "write through symlink",
+			entries: []tarEntry{tarDir("python/real/"), tarSymlink("python/link", "real"), tarFile("python/link/file", "x")},
+			wantErr: "through symlink",
+		},
+		{
+			name:    "hard link to outside of prefix",
+			entries: []tarEntry{tarFile("other/secret", "x"), tarHardlink("python/secret", "other/secret")},
+			wantErr: "link to outside",
+		},
+		{
+			name:    "hard link to outside of archive",
+			entries: []tarEntry{tarHardlink("python/passwd", "python/../../etc/passwd")},
+			wantErr: "link to outside",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.symlink && runtime.GOOS == "windows" {
+				t.Skip("symlink is not available")
+			}
+			tmp := t.TempDir()
+			path := filepath.Join(tmp, "archive.tar.gz")
+			writeTarGz(t, path, tt.entries...)
+			dest := filepath.Join(tmp, "dest")
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractArchive(path, dest, "python/")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("extractArchive error = %v, want %q", err, tt.wantErr)
+				}
+				if _, err := os.Stat(filepath.Join(tmp, "evil")); err == nil {
+					t.Error("file is written outside of dest")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractArchive: %v", err)
+			}
+			for name, content := range tt.want {
+				b, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+				if err != nil || string(b) != content {
+					t.Errorf("%s = %q, %v, want %q", name, b, err, content)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dest, "file")); err == nil {
+				t.Error("entry outside of prefix is extracted")
+			}
+		})
+	}
+}
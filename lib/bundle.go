@@ -0,0 +1,466 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// bundle is a tar file to install python without network.
+//   - pim-bundle.json: manifest. (always the first entry)
+//   - cache.json: the version cache.
+//   - installer/<file name>: verified installers. sha256 is recorded in the manifest.
+//   - installer/<file name>.sigstore: sigstore bundle of the installer, if exists. (since schema version 2)
+//
+// the manifest is written by anyone who has the bundle, so it does not make installers verified.
+// imported installers are verified by the sigstore bundle, or by the published hash when online.
+// "<file>.bundle" records where the installer is imported from until it is verified. (see bundleProvenance)
+const (
+	bundleSchemaVersion = 2
+	bundleManifestName  = "pim-bundle.json"
+	bundleCacheName     = "cache.json"
+	bundleInstallerDir  = "installer/"
+	bundleSuffix        = ".bundle"
+)
+
+type bundleInstaller struct {
+	Version  string `json:"version"`
+	FileName string `json:"file_name"`
+	Sha256   string `json:"sha256"`
+}
+
+type bundleManifest struct {
+	SchemaVersion int               `json:"schema_version"`
+	Provider      string            `json:"provider"`
+	Distribution  string            `json:"distribution"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	Created       time.Time         `json:"created"`
+	Installers    []bundleInstaller `json:"installers"`
+}
+
+// bundleProvenance is where the installer is imported from.
+type bundleProvenance struct {
+	Bundle   string    `json:"bundle"`
+	Version  string    `json:"version"`
+	Sha256   string    `json:"sha256"`
+	Imported time.Time `json:"imported"`
+}
+
+func readBundleProvenance(filePath string) (bundleProvenance, bool) {
+	var provenance bundleProvenance
+	byteValue, err := os.ReadFile(filePath + bundleSuffix)
+	if err != nil {
+		return provenance, false
+	}
+	if err := json.Unmarshal(byteValue, &provenance); err != nil || provenance.Sha256 == "" {
+		return provenance, false
+	}
+	return provenance, true
+}
+
+// verifyImportedInstaller verifies the installer imported from a bundle by its sigstore bundle, without network.
+func verifyImportedInstaller(ctx context.Context, config Config, version Version, filePath string, provenance bundleProvenance) error {
+	fileName := filepath.Base(filePath)
+	if !isFile(filePath + sigstoreSuffix) {
+		return fmt.Errorf(
+			"%s is imported from %s without signature, and can not be verified offline. (verify it online, or import the bundle with --trust)",
+			fileName, provenance.Bundle,
+		)
+	}
+	verified, err := verifySigstore(ctx, config, version, filePath, "")
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return fmt.Errorf(
+			"%s is imported from %s, and cosign is required to verify it offline. (verify it online, or import the bundle with --trust)",
+			fileName, provenance.Bundle,
+		)
+	}
+	fmt.Fprintf(MessageOut(), "verified by sigstore bundle: %s\n", fileName)
+	return markVerified(filePath, provenance.Sha256)
+}
+
+// downloadBundledArtifact downloads (or reuses) the verified artifact of the newest version matching spec.
+func downloadBundledArtifact(ctx context.Context, config Config, provider Provider, spec Specifier) (Version, string, error) {
+	versions := findMatchingVersions(config, spec)
+	for _, version := range versions {
+		_, path, err := downloadArtifact(ctx, config, provider, version, runtime.GOARCH)
+		if err == nil {
+			return version, path, nil
+		}
+		var sErr *StatusError
+		if len(versions) > 1 && errors.As(err, &sErr) {
+			continue
+		}
+		return Version{}, "", err
+	}
+	return Version{}, "", &NotFoundError{fmt.Sprintf("can not found installable version of %s", spec.String())}
+}
+
+// CreateBundle writes the version cache and the installers of the newest versions matching specs into out.
+func CreateBundle(ctx context.Context, config Config, provider Provider, specs []Specifier, out string) error {
+	if err := fetchLatestVersions(ctx, config, provider); err != nil {
+		return err
+	}
+	d, err := distribution(config)
+	if err != nil {
+		return err
+	}
+
+	manifest := bundleManifest{
+		SchemaVersion: bundleSchemaVersion,
+		Provider:      provider.Name(),
+		Distribution:  d,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		Created:       time.Now().UTC(),
+	}
+	var paths []string
+	for _, spec := range specs {
+		version, path, err := downloadBundledArtifact(ctx, config, provider, spec)
+		if err != nil {
+			return err
+		}
+		fileName := filepath.Base(path)
+		// verifyArtifact writes the sidecar only if the file is verified.
+		sum, err := os.ReadFile(path + sha256Suffix)
+		if err != nil {
+			return fmt.Errorf("%s is not verified. only verified installer can be bundled", fileName)
+		}
+		fmt.Fprintf(MessageOut(), "bundle: %s (%s)\n", version.String(), fileName)
+		manifest.Installers = append(manifest.Installers, bundleInstaller{version.String(), fileName, strings.TrimSpace(string(sum))})
+		paths = append(paths, path)
+	}
+
+	return writeBundle(ctx, out, manifest, paths)
+}
+
+func writeBundle(ctx context.Context, out string, manifest bundleManifest, paths []string) error {
+	// same as downloads, incomplete bundle is never left as out.
+	partPath := out + partSuffix
+	f, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	err = writeBundleEntries(ctx, f, manifest, paths)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		if rErr := os.Remove(partPath); rErr != nil {
+			return errors.Join(err, rErr)
+		}
+		return err
+	}
+	return os.Rename(partPath, out)
+}
+
+func writeBundleEntries(ctx context.Context, w io.Writer, manifest bundleManifest, paths []string) error {
+	tw := tar.NewWriter(w)
+	if err := writeBundleManifest(tw, manifest); err != nil {
+		return err
+	}
+	if err := addVersionCache(ctx, tw); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := addTarFile(tw, bundleInstallerDir+filepath.Base(path), path); err != nil {
+			return err
+		}
+		// sigstore bundle is saved by verifySigstore.
+		if isFile(path + sigstoreSuffix) {
+			if err := addTarFile(tw, bundleInstallerDir+filepath.Base(path)+sigstoreSuffix, path+sigstoreSuffix); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
+
+// addVersionCache adds the version cache. it is not replaced by other pim process while reading.
+func addVersionCache(ctx context.Context, tw *tar.Writer) error {
+	unlock, err := lockFile(ctx, cacheLockName)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(unlock)
+
+	return addTarFile(tw, bundleCacheName, versionCacheFile)
+}
+
+func writeBundleManifest(tw *tar.Writer, manifest bundleManifest) error {
+	byteValue, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: bundleManifestName, Mode: 0644, Size: int64(len(byteValue)), ModTime: manifest.Created}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = tw.Write(byteValue)
+	return err
+}
+
+func addTarFile(tw *tar.Writer, name string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(f.Close)
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// ImportBundle imports the version cache and the installers in the bundle at path.
+// installers are checked with sha256 in the manifest, and verified by their sigstore bundles if cosign is available.
+// installers which can not be verified yet are verified when used. if trust, they are marked as verified without it.
+func ImportBundle(ctx context.Context, config Config, path string, trust bool) error {
+	bundlePath, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(f.Close)
+
+	var manifest *bundleManifest
+	var imported []string
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case header.Name == bundleManifestName:
+			if manifest, err = readBundleManifest(tr); err != nil {
+				return err
+			}
+		case manifest == nil:
+			return fmt.Errorf("invalid bundle: %s is not the first entry", bundleManifestName)
+		case header.Name == bundleCacheName:
+			if err := importVersionCache(ctx, tr); err != nil {
+				return err
+			}
+		case strings.HasPrefix(header.Name, bundleInstallerDir) && strings.HasSuffix(header.Name, sigstoreSuffix):
+			fileName := strings.TrimSuffix(strings.TrimPrefix(header.Name, bundleInstallerDir), sigstoreSuffix)
+			if err := importSigstoreBundle(ctx, tr, *manifest, fileName); err != nil {
+				return err
+			}
+		case strings.HasPrefix(header.Name, bundleInstallerDir):
+			fileName := strings.TrimPrefix(header.Name, bundleInstallerDir)
+			if err := importInstaller(ctx, tr, *manifest, bundlePath, fileName); err != nil {
+				return err
+			}
+			imported = append(imported, fileName)
+		}
+	}
+	if manifest == nil {
+		return fmt.Errorf("invalid bundle: %s is not found", bundleManifestName)
+	}
+
+	for _, fileName := range imported {
+		filePath := filepath.Join(installerCacheDir, fileName)
+		provenance, ok := readBundleProvenance(filePath)
+		if !ok {
+			continue
+		}
+		if trust {
+			fmt.Fprintf(MessageOut(), "warning: %s is trusted without verification\n", fileName)
+			if err := markVerified(filePath, provenance.Sha256); err != nil {
+				return err
+			}
+			continue
+		}
+		version, err := NewVersion(provenance.Version)
+		if err != nil {
+			return fmt.Errorf("invalid bundle manifest: %w", err)
+		}
+		if err := verifyImportedInstaller(ctx, config, version, filePath, provenance); err != nil {
+			var vErr *VerificationError
+			if errors.As(err, &vErr) {
+				return err
+			}
+			fmt.Fprintf(MessageOut(), "not verified yet: %s\n", err.Error())
+		}
+	}
+	return nil
+}
+
+func readBundleManifest(r io.Reader) (*bundleManifest, error) {
+	var manifest bundleManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if manifest.SchemaVersion > bundleSchemaVersion {
+		return nil, fmt.Errorf("unsupported bundle schema version: %d (update pim)", manifest.SchemaVersion)
+	}
+	if manifest.OS != runtime.GOOS || manifest.Arch != runtime.GOARCH {
+		fmt.Fprintf(MessageOut(), "warning: the bundle is created for %s/%s\n", manifest.OS, manifest.Arch)
+	}
+	fmt.Fprintf(MessageOut(), "bundle: provider %s, distribution %s\n", manifest.Provider, manifest.Distribution)
+	return &manifest, nil
+}
+
+// importVersionCache replaces the version cache, unless the local one of the same provider is newer.
+func importVersionCache(ctx context.Context, r io.Reader) error {
+	byteValue, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	cache, err := decodeCache(byteValue)
+	if err != nil {
+		return fmt.Errorf("invalid version cache in bundle: %w", err)
+	}
+
+	unlock, err := lockFile(ctx, cacheLockName)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(unlock)
+
+	if local, err := loadCacheFile(); err == nil && local.Provider == cache.Provider && !cache.UpdateDate.After(local.UpdateDate) {
+		fmt.Fprintln(MessageOut(), "skip: the local version cache is newer than the bundled one")
+		return nil
+	}
+
+	if err := writeFileAtomic(versionCacheFile, byteValue); err != nil {
+		return err
+	}
+	fmt.Fprintln(MessageOut(), "imported: version cache")
+	return nil
+}
+
+// findBundleInstaller returns the installer of fileName in the manifest.
+func findBundleInstaller(manifest bundleManifest, fileName string) (bundleInstaller, error) {
+	// file name must not escape installerCacheDir.
+	if fileName == filepath.Base(fileName) {
+		for _, installer := range manifest.Installers {
+			if installer.FileName == fileName && installer.Sha256 != "" {
+				return installer, nil
+			}
+		}
+	}
+	return bundleInstaller{}, fmt.Errorf("invalid bundle: unknown installer %s", fileName)
+}
+
+func importSigstoreBundle(ctx context.Context, r io.Reader, manifest bundleManifest, fileName string) error {
+	if _, err := findBundleInstaller(manifest, fileName); err != nil {
+		return err
+	}
+	byteValue, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(unlock)
+
+	return writeFileAtomic(filepath.Join(installerCacheDir, fileName+sigstoreSuffix), byteValue)
+}
+
+// importInstaller copies the installer into installerCacheDir, and records its provenance. it is not marked as verified.
+func importInstaller(ctx context.Context, r io.Reader, manifest bundleManifest, bundlePath string, fileName string) error {
+	installer, err := findBundleInstaller(manifest, fileName)
+	if err != nil {
+		return err
+	}
+	expected := installer.Sha256
+
+	unlock, err := lockFile(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(unlock)
+
+	filePath := filepath.Join(installerCacheDir, fileName)
+	partPath := filePath + partSuffix
+	out, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(out, io.TeeReader(r, hash))
+	if cErr := out.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(expected, actual) {
+		return quarantine(partPath, fmt.Sprintf("sha256 mismatch with bundle manifest. expected: %s, actual: %s", expected, actual))
+	}
+
+	// verified installer of the same file is kept verified.
+	if b, err := os.ReadFile(filePath + sha256Suffix); err == nil && strings.EqualFold(strings.TrimSpace(string(b)), expected) {
+		fmt.Fprintf(MessageOut(), "skip: %s is already verified\n", fileName)
+		return os.Remove(partPath)
+	}
+	for _, suffix := range []string{sha256Suffix, bundleSuffix} {
+		if err := os.Remove(filePath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	provenance, err := json.Marshal(bundleProvenance{bundlePath, installer.Version, strings.ToLower(expected), time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(partPath, filePath); err != nil {
+		return err
+	}
+	fmt.Fprintf(MessageOut(), "imported: %s\n", fileName)
+	return os.WriteFile(filePath+bundleSuffix, provenance, 0644)
+}
@@ -0,0 +1,183 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// writeTestBundle writes a bundle with entries. name of entry is the name in the bundle.
+func writeTestBundle(t *testing.T, entries ...tarEntry) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bundle.tar")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deferErrCheck(f.Close)
+
+	tw := tar.NewWriter(f)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testBundleManifest(t *testing.T, schemaVersion int, installers ...bundleInstaller) tarEntry {
+	t.Helper()
+	b, err := json.Marshal(bundleManifest{
+		SchemaVersion: schemaVersion,
+		Provider:      DefaultProvider,
+		Distribution:  DistributionStandalone,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		Installers:    installers,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tarFile(bundleManifestName, string(b))
+}
+
+func TestImportBundle(t *testing.T) {
+	const fileName = "cpython-3.12.7.tar.gz"
+	installer := "installer body"
+	sum := sha256Hex([]byte(installer))
+	cache := tarFile(bundleCacheName, `{"schema_version": 1, "provider": "cpython", "versions": ["3.12.7"]}`)
+
+	tests := []struct {
+		name    string
+		entries func(t *testing.T) []tarEntry
+		trust   bool
+		wantErr string
+		// wantVerified is true if the installer is marked as verified.
+		wantVerified bool
+	}{
+		{
+			name: "not trusted",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion, bundleInstaller{"3.12.7", fileName, sum}), cache, tarFile(bundleInstallerDir+fileName, installer)}
+			},
+		},
+		{
+			name: "trusted",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion, bundleInstaller{"3.12.7", fileName, sum}), cache, tarFile(bundleInstallerDir+fileName, installer)}
+			},
+			trust:        true,
+			wantVerified: true,
+		},
+		{
+			name: "sha256 mismatch with manifest",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion, bundleInstaller{"3.12.7", fileName, sum}), tarFile(bundleInstallerDir+fileName, "tampered")}
+			},
+			trust:   true,
+			wantErr: "verification_failed",
+		},
+		{
+			name: "installer not in manifest",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion), tarFile(bundleInstallerDir+fileName, installer)}
+			},
+			trust:   true,
+			wantErr: "unknown installer",
+		},
+		{
+			name: "installer out of cache directory",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion, bundleInstaller{"3.12.7", "../" + fileName, sum}), tarFile(bundleInstallerDir+"../"+fileName, installer)}
+			},
+			trust:   true,
+			wantErr: "unknown installer",
+		},
+		{
+			name: "manifest is not the first entry",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{cache, testBundleManifest(t, bundleSchemaVersion)}
+			},
+			wantErr: "is not the first entry",
+		},
+		{
+			name: "newer schema",
+			entries: func(t *testing.T) []tarEntry {
+				return []tarEntry{testBundleManifest(t, bundleSchemaVersion+1)}
+			},
+			wantErr: "unsupported bundle schema version",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempDirs(t)
+			bundle := writeTestBundle(t, tt.entries(t)...)
+
+			err := ImportBundle(context.Background(), Config{Offline: true}, bundle, tt.trust)
+			filePath := filepath.Join(installerCacheDir, fileName)
+			if tt.wantErr != "" {
+				if code, _ := classifyError(err); err == nil || code != tt.wantErr && !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ImportBundle error = %v, want %s", err, tt.wantErr)
+				}
+				if isFile(filePath) || isFile(filepath.Join(filepath.Dir(installerCacheDir), fileName)) {
+					t.Errorf("invalid installer is imported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportBundle: %v", err)
+			}
+
+			if b, err := os.ReadFile(filePath); err != nil || string(b) != installer {
+				t.Fatalf("imported installer = %q, %v", b, err)
+			}
+			if _, err := loadCacheFile(); err != nil {
+				t.Errorf("version cache is not imported: %v", err)
+			}
+			b, err := os.ReadFile(filePath + sha256Suffix)
+			if verified := err == nil && strings.TrimSpace(string(b)) == sum; verified != tt.wantVerified {
+				t.Errorf("verified = %t, want %t", verified, tt.wantVerified)
+			}
+			// provenance is kept until verified.
+			if _, ok := readBundleProvenance(filePath); ok == tt.wantVerified {
+				t.Errorf("provenance = %t, want %t", ok, !tt.wantVerified)
+			}
+		})
+	}
+}
@@ -25,23 +25,46 @@ SOFTWARE.
 package lib
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/spf13/cobra"
-	"io"
 	"os"
 	"path/filepath"
 	"time"
 )
 
+const (
+	defaultCacheTTL = 24 * time.Hour
+)
+
+// cacheMigrations[i] migrates raw cache of schema version i to i+1.
+var cacheMigrations = []func(cache map[string]any){
+	// 0: before schema_version. cache without provider is created by cpython provider.
+	func(cache map[string]any) {
+		if p, _ := cache["provider"].(string); p == "" {
+			cache["provider"] = DefaultProvider
+		}
+	},
+}
+
+// cacheSchemaVersion is incremented when VersionCache is changed incompatibly. (add migration)
+var cacheSchemaVersion = len(cacheMigrations)
+
 type VersionCache struct {
-	UpdateDate            time.Time       `json:"update_date"`
-	AllVersions           []Version       `json:"versions"`
-	FailedMinimumVersions map[int]Version `json:"failed_minimum_versions"`
+	SchemaVersion         int                     `json:"schema_version"`
+	Provider              string                  `json:"provider"`
+	UpdateDate            time.Time               `json:"update_date"`
+	AllVersions           []Version               `json:"versions"`
+	FailedMinimumVersions map[int]Version         `json:"failed_minimum_versions"`
+	GitHubPages           map[int]GitHubPageCache `json:"github_pages,omitempty"`
 }
 
 var (
 	cacheDir         string
 	versionCacheFile string
+	// RefreshCache ignores fresh version cache, and fetches versions again.
+	RefreshCache bool
 )
 
 func init() {
@@ -57,50 +80,112 @@ func init() {
 	versionCacheFile = filepath.Join(cacheDir, "cache.json")
 }
 
-func readCache() (bool, error) {
+// decodeCache decodes the version cache, migrating it from older schema version.
+func decodeCache(byteValue []byte) (VersionCache, error) {
 	var cache VersionCache
-	cacheFile, err := os.Open(versionCacheFile)
+	var raw map[string]any
+	if err := json.Unmarshal(byteValue, &raw); err != nil {
+		return cache, err
+	}
+
+	version, _ := raw["schema_version"].(float64)
+	if int(version) > cacheSchemaVersion {
+		return cache, fmt.Errorf("version cache is created by newer pim. (schema version: %d)", int(version))
+	}
+	for i := int(version); i < cacheSchemaVersion; i++ {
+		cacheMigrations[i](raw)
+	}
+	raw["schema_version"] = cacheSchemaVersion
+
+	byteValue, err := json.Marshal(raw)
 	if err != nil {
-		return true, err
+		return cache, err
 	}
-	defer deferErrCheck(cacheFile.Close)
+	err = json.Unmarshal(byteValue, &cache)
+	return cache, err
+}
 
-	byteValue, err := io.ReadAll(cacheFile)
+// loadCacheFile reads versionCacheFile. caller must hold the cache lock.
+func loadCacheFile() (VersionCache, error) {
+	byteValue, err := os.ReadFile(versionCacheFile)
 	if err != nil {
-		return true, err
+		return VersionCache{}, err
 	}
+	return decodeCache(byteValue)
+}
 
-	err = json.Unmarshal(byteValue, &cache)
+// readCache reads the version cache. if offline, expired cache is also used.
+// if the cache can not be read (e.g. broken or created by newer pim), it is discarded and "need" is true.
+// error without "need" is not about the cache. (e.g. canceled while waiting for the lock)
+func readCache(ctx context.Context, config Config, ttl time.Duration) (bool, error) {
+	unlock, err := lockFile(ctx, cacheLockName)
+	if err != nil {
+		return false, err
+	}
+	defer deferErrCheck(unlock)
 
+	cache, err := loadCacheFile()
 	if err != nil {
+		if WithVerbose > 0 && !os.IsNotExist(err) {
+			fmt.Fprintf(MessageOut(), "version cache is discarded: %s\n", err.Error())
+		}
 		return true, err
 	}
+	if cache.Provider != fetchedProvider {
+		return true, nil
+	}
 
 	// always use cache. maybe add key-value pair, but do not change in cached.
 	if v := cache.FailedMinimumVersions; v != nil {
 		failedMinimumVersions = v
 	}
+	// used for conditional requests, even if cache is expired.
+	if v := cache.GitHubPages; v != nil {
+		githubPages = v
+	}
 
-	if time.Now().Compare(cache.UpdateDate.Add(time.Hour*24)) > 0 {
+	if !config.Offline && (RefreshCache || time.Now().Compare(cache.UpdateDate.Add(ttl)) > 0) {
 		return true, nil
 	}
 
-	saveVersions(cache.AllVersions)
+	saveVersions(cache.AllVersions, cache.UpdateDate)
 
 	return false, nil
 }
 
-func saveCache() error {
-	cacheFile, err := os.Create(versionCacheFile)
+// mergeFailedMinimumVersions merges failed versions recorded by other pim process.
+func mergeFailedMinimumVersions(failed map[int]Version) {
+	for minor, v := range failed {
+		if cur, ok := failedMinimumVersions[minor]; !ok || cur.GreaterThan(v) {
+			failedMinimumVersions[minor] = v
+		}
+	}
+}
+
+// saveCache writes the version cache atomically.
+// other pim process may update the cache after this process read it, so failed versions are merged.
+// UpdateDate is the date versions are fetched, so saving only failed versions does not make the cache fresh.
+func saveCache(ctx context.Context) error {
+	unlock, err := lockFile(ctx, cacheLockName)
 	if err != nil {
 		return err
 	}
-	defer deferErrCheck(cacheFile.Close)
+	defer deferErrCheck(unlock)
 
 	cache := VersionCache{
-		UpdateDate:            time.Now().UTC(),
+		SchemaVersion:         cacheSchemaVersion,
+		Provider:              fetchedProvider,
+		UpdateDate:            fetchedAt,
 		AllVersions:           allVersions,
 		FailedMinimumVersions: failedMinimumVersions,
+		GitHubPages:           githubPages,
+	}
+	if saved, err := loadCacheFile(); err == nil && saved.Provider == fetchedProvider {
+		mergeFailedMinimumVersions(saved.FailedMinimumVersions)
+		if fetchedAt.IsZero() {
+			// versions are not fetched (nor read) by this process.
+			cache.UpdateDate, cache.AllVersions = saved.UpdateDate, saved.AllVersions
+		}
 	}
 
 	byteValue, err := json.Marshal(cache)
@@ -108,10 +193,5 @@ func saveCache() error {
 		return err
 	}
 
-	_, err = cacheFile.Write(byteValue)
-	return err
-}
-
-func CleanCache() error {
-	return os.RemoveAll(cacheDir)
+	return writeFileAtomic(versionCacheFile, byteValue)
 }
@@ -0,0 +1,166 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeCache(t *testing.T) {
+	tests := []struct {
+		name         string
+		json         string
+		wantProvider string
+		wantVersions int
+		wantErr      string
+	}{
+		{name: "before schema version", json: `{"update_date": "2024-10-01T00:00:00Z", "versions": ["3.12.7", "3.13.0"]}`, wantProvider: DefaultProvider, wantVersions: 2},
+		{name: "before schema version with provider", json: `{"provider": "python.org", "versions": ["3.12.7"]}`, wantProvider: PythonOrgProvider, wantVersions: 1},
+		{name: "current", json: fmt.Sprintf(`{"schema_version": %d, "provider": "python.org", "versions": []}`, cacheSchemaVersion), wantProvider: PythonOrgProvider},
+		{name: "newer schema", json: fmt.Sprintf(`{"schema_version": %d, "provider": "cpython"}`, cacheSchemaVersion+1), wantErr: "newer pim"},
+		{name: "broken", json: `{"schema_version": 1,`, wantErr: "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := decodeCache([]byte(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeCache error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCache: %v", err)
+			}
+			if cache.SchemaVersion != cacheSchemaVersion || cache.Provider != tt.wantProvider || len(cache.AllVersions) != tt.wantVersions {
+				t.Errorf("decodeCache = %+v", cache)
+			}
+		})
+	}
+}
+
+// writeTestCache writes the version cache updated at updated.
+func writeTestCache(t *testing.T, provider string, updated time.Time, versions ...Version) {
+	t.Helper()
+	b, err := json.Marshal(VersionCache{SchemaVersion: cacheSchemaVersion, Provider: provider, UpdateDate: updated, AllVersions: versions})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(versionCacheFile, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		age      time.Duration
+		ttl      time.Duration
+		offline  bool
+		refresh  bool
+		noCache  bool
+		wantNeed bool
+	}{
+		{name: "fresh", provider: DefaultProvider, age: time.Hour, ttl: defaultCacheTTL},
+		{name: "expired", provider: DefaultProvider, age: 25 * time.Hour, ttl: defaultCacheTTL, wantNeed: true},
+		{name: "short ttl", provider: DefaultProvider, age: time.Hour, ttl: 30 * time.Minute, wantNeed: true},
+		{name: "refresh", provider: DefaultProvider, age: time.Hour, ttl: defaultCacheTTL, refresh: true, wantNeed: true},
+		{name: "expired but offline", provider: DefaultProvider, age: 30 * 24 * time.Hour, ttl: defaultCacheTTL, offline: true},
+		{name: "other provider", provider: PythonOrgProvider, age: time.Hour, ttl: defaultCacheTTL, wantNeed: true},
+		{name: "no cache", noCache: true, ttl: defaultCacheTTL, wantNeed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempDirs(t)
+			resetVersions(t)
+			fetchedProvider = DefaultProvider
+			saved := RefreshCache
+			RefreshCache = tt.refresh
+			t.Cleanup(func() { RefreshCache = saved })
+
+			updated := time.Now().Add(-tt.age).UTC().Truncate(time.Second)
+			if !tt.noCache {
+				writeTestCache(t, tt.provider, updated, Version{Major: 3, Minor: 12, Micro: 7})
+			}
+
+			need, _ := readCache(context.Background(), Config{Offline: tt.offline}, tt.ttl)
+			if need != tt.wantNeed {
+				t.Fatalf("readCache need = %t, want %t", need, tt.wantNeed)
+			}
+			if !need && (len(allVersions) != 1 || !fetchedAt.Equal(updated)) {
+				t.Errorf("versions = %v (fetched at %s), want cached ones (%s)", allVersions, fetchedAt, updated)
+			}
+		})
+	}
+}
+
+func TestSaveCache(t *testing.T) {
+	savedAt := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	fetched := time.Date(2024, 10, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		// fetchedAt is zero if versions are not fetched by this process.
+		fetchedAt    time.Time
+		wantDate     time.Time
+		wantVersions int
+	}{
+		{name: "fetched", fetchedAt: fetched, wantDate: fetched, wantVersions: 2},
+		{name: "only failed versions", wantDate: savedAt, wantVersions: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempDirs(t)
+			resetVersions(t)
+			writeTestCache(t, DefaultProvider, savedAt, Version{Major: 3, Minor: 12, Micro: 7})
+
+			fetchedProvider = DefaultProvider
+			if !tt.fetchedAt.IsZero() {
+				saveVersions([]Version{{Major: 3, Minor: 12, Micro: 7}, {Major: 3, Minor: 13}}, tt.fetchedAt)
+			}
+			failedMinimumVersions[12] = Version{Major: 3, Minor: 12, Micro: 1}
+			if err := saveCache(context.Background()); err != nil {
+				t.Fatalf("saveCache: %v", err)
+			}
+
+			cache, err := loadCacheFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !cache.UpdateDate.Equal(tt.wantDate) || len(cache.AllVersions) != tt.wantVersions {
+				t.Errorf("saved cache = {UpdateDate: %s, versions: %v}, want {%s, %d versions}", cache.UpdateDate, cache.AllVersions, tt.wantDate, tt.wantVersions)
+			}
+			if _, ok := cache.FailedMinimumVersions[12]; !ok {
+				t.Errorf("failed version is not saved: %v", cache.FailedMinimumVersions)
+			}
+		})
+	}
+}
@@ -0,0 +1,292 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CleanOptions selects what is removed by Clean.
+// installer filters (OlderThan, KeepInstalled and MaxSize) are combined. if no filter is set, all installers are removed.
+type CleanOptions struct {
+	VersionsCache bool
+	Installers    bool
+	// remove installers not used for longer than this. zero means no limit.
+	OlderThan time.Duration
+	// keep installers needed to uninstall installed python.
+	KeepInstalled bool
+	// remove installers from the least recently used, until the total size is within this. negative means no limit.
+	MaxSize int64
+	DryRun  bool
+}
+
+// cachedInstaller is a file in installerCacheDir, with its sidecar files and partial download.
+type cachedInstaller struct {
+	name        string
+	paths       []string
+	size        int64
+	modTime     time.Time // last used time.
+	quarantined bool
+}
+
+var (
+	sizeUnits = map[string]int64{
+		"": 1, "B": 1,
+		"K": 1 << 10, "KB": 1 << 10, "KIB": 1 << 10,
+		"M": 1 << 20, "MB": 1 << 20, "MIB": 1 << 20,
+		"G": 1 << 30, "GB": 1 << 30, "GIB": 1 << 30,
+		"T": 1 << 40, "TB": 1 << 40, "TIB": 1 << 40,
+	}
+)
+
+// ParseAge parses duration like time.ParseDuration, and also "d" (day) and "w" (week) unit. (e.g. "30d", "2w", "12h")
+func ParseAge(value string) (time.Duration, error) {
+	for unit, d := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(value, unit); ok {
+			if f, err := strconv.ParseFloat(n, 64); err == nil && f >= 0 {
+				return time.Duration(f * float64(d)), nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (e.g. \"30d\", \"2w\", \"12h\")", value)
+	}
+	return d, nil
+}
+
+// ParseSize parses size like "2GB", "500MB" or "1024". units are 1024 based, same as shown by pim.
+func ParseSize(value string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(value))
+	i := strings.IndexFunc(v, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(v)
+	}
+	n, err := strconv.ParseFloat(v[:i], 64)
+	unit, ok := sizeUnits[strings.TrimSpace(v[i:])]
+	if err != nil || !ok || n < 0 {
+		return 0, fmt.Errorf("invalid size: %s (e.g. \"2GB\", \"500MB\")", value)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// retentionOptions returns CleanOptions from the retention rules in config.
+// CleanOlderThan, CleanMaxSize and CleanKeepInstalled are used.
+func retentionOptions(config Config) (CleanOptions, error) {
+	options := CleanOptions{Installers: true, KeepInstalled: config.CleanKeepInstalled, MaxSize: -1}
+	var err error
+	if config.CleanOlderThan != "" {
+		if options.OlderThan, err = ParseAge(config.CleanOlderThan); err != nil {
+			return options, fmt.Errorf("invalid CleanOlderThan: %w", err)
+		}
+	}
+	if config.CleanMaxSize != "" {
+		if options.MaxSize, err = ParseSize(config.CleanMaxSize); err != nil {
+			return options, fmt.Errorf("invalid CleanMaxSize: %w", err)
+		}
+	}
+	return options, nil
+}
+
+// pruneByRetention removes installers by the retention rules in config, if any rule is set.
+// it is called after install, and failure is only warned.
+func pruneByRetention(ctx context.Context, config Config) {
+	options, err := retentionOptions(config)
+	if err == nil && options.OlderThan <= 0 && options.MaxSize < 0 {
+		return
+	}
+	if err == nil {
+		err = cleanInstallers(ctx, config, options)
+	}
+	if err != nil {
+		fmt.Fprintf(MessageOut(), "warning: can not clean installers: %s\n", err.Error())
+	}
+}
+
+// listCachedInstallers returns cached installers, the least recently used first.
+// quarantined files are also returned.
+func listCachedInstallers() ([]cachedInstaller, error) {
+	installers := make(map[string]*cachedInstaller)
+	var names []string
+	for _, dir := range []string{installerCacheDir, quarantineDir} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			name := entry.Name()
+			if dir == quarantineDir {
+				name = filepath.Join("quarantine", name)
+			} else {
+				for _, suffix := range []string{sha256Suffix, sigstoreSuffix, bundleSuffix, partSuffix} {
+					name = strings.TrimSuffix(name, suffix)
+				}
+			}
+			installer, ok := installers[name]
+			if !ok {
+				installer = &cachedInstaller{name: name, quarantined: dir == quarantineDir}
+				installers[name] = installer
+				names = append(names, name)
+			}
+			installer.paths = append(installer.paths, filepath.Join(dir, entry.Name()))
+			installer.size += info.Size()
+			if info.ModTime().After(installer.modTime) {
+				installer.modTime = info.ModTime()
+			}
+		}
+	}
+
+	result := make([]cachedInstaller, 0, len(names))
+	for _, name := range names {
+		result = append(result, *installers[name])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].modTime.Before(result[j].modTime) })
+	return result, nil
+}
+
+// selectInstallersToClean returns installers removed by options, and the total size after removing.
+func selectInstallersToClean(config Config, options CleanOptions, installers []cachedInstaller) ([]cachedInstaller, int64) {
+	kept := make(map[string]bool)
+	if options.KeepInstalled {
+		for _, installation := range getInstallations(config) {
+			if path, ok := installation.CachedInstaller(config); ok {
+				kept[filepath.Base(path)] = true
+			}
+		}
+	}
+
+	var total int64
+	for _, installer := range installers {
+		total += installer.size
+	}
+	noFilter := options.OlderThan <= 0 && options.MaxSize < 0
+	var removed []cachedInstaller
+	for _, installer := range installers {
+		if kept[installer.name] {
+			continue
+		}
+		isOld := options.OlderThan > 0 && time.Since(installer.modTime) > options.OlderThan
+		isOver := options.MaxSize >= 0 && total > options.MaxSize
+		if noFilter || isOld || isOver {
+			removed = append(removed, installer)
+			total -= installer.size
+		}
+	}
+	return removed, total
+}
+
+func cleanInstallers(ctx context.Context, config Config, options CleanOptions) error {
+	installers, err := listCachedInstallers()
+	if err != nil {
+		return err
+	}
+	removed, total := selectInstallersToClean(config, options, installers)
+
+	var freed int64
+	for _, installer := range removed {
+		if options.DryRun {
+			fmt.Fprintf(MessageOut(), "would remove: %s (%s)\n", installer.name, formatBytes(installer.size))
+			freed += installer.size
+			continue
+		}
+		if err := removeCachedInstaller(ctx, installer); err != nil {
+			return err
+		}
+		fmt.Fprintf(MessageOut(), "removed: %s (%s)\n", installer.name, formatBytes(installer.size))
+		freed += installer.size
+	}
+
+	if options.DryRun {
+		fmt.Fprintf(MessageOut(), "installers: %s would be freed, %s would be left.\n", formatBytes(freed), formatBytes(total))
+	} else if len(removed) > 0 || WithVerbose > 0 {
+		fmt.Fprintf(MessageOut(), "installers: %s freed, %s left.\n", formatBytes(freed), formatBytes(total))
+	}
+	return nil
+}
+
+func removeCachedInstaller(ctx context.Context, installer cachedInstaller) error {
+	if !installer.quarantined {
+		// other pim process may be downloading or verifying it.
+		unlock, err := lockFile(ctx, installer.name)
+		if err != nil {
+			return err
+		}
+		defer deferErrCheck(unlock)
+	}
+	for _, path := range installer.paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func cleanVersionsCache(ctx context.Context, dryRun bool) error {
+	if !isFile(versionCacheFile) {
+		return nil
+	}
+	if dryRun {
+		fmt.Fprintln(MessageOut(), "would remove: version cache")
+		return nil
+	}
+
+	unlock, err := lockFile(ctx, cacheLockName)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(unlock)
+
+	if err := os.Remove(versionCacheFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	fmt.Fprintln(MessageOut(), "removed: version cache")
+	return nil
+}
+
+// Clean removes the version cache and cached installers selected by options.
+func Clean(ctx context.Context, config Config, options CleanOptions) error {
+	if options.VersionsCache {
+		if err := cleanVersionsCache(ctx, options.DryRun); err != nil {
+			return err
+		}
+	}
+	if options.Installers {
+		return cleanInstallers(ctx, config, options)
+	}
+	return nil
+}
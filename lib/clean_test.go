@@ -0,0 +1,164 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30d", want: 30 * 24 * time.Hour},
+		{value: "2w", want: 14 * 24 * time.Hour},
+		{value: "1.5d", want: 36 * time.Hour},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "90m", want: 90 * time.Minute},
+		{value: "0d", want: 0},
+		{value: "", wantErr: true},
+		{value: "30", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "1y", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAge(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseAge(%q) = %s, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseAge(%q) = %s, %v, want %s", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "1024", want: 1024},
+		{value: "100B", want: 100},
+		{value: "2GB", want: 2 << 30},
+		{value: "500MB", want: 500 << 20},
+		{value: "1.5K", want: 1536},
+		{value: "1 GiB", want: 1 << 30},
+		{value: "3tb", want: 3 << 40},
+		{value: " 0 ", want: 0},
+		{value: "", wantErr: true},
+		{value: "GB", wantErr: true},
+		{value: "2PB", wantErr: true},
+		{value: "-1GB", wantErr: true},
+		{value: "1.2.3MB", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseSize(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseSize(%q) = %d, want error", tt.value, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, %v, want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectInstallersToClean(t *testing.T) {
+	useTempDirs(t)
+	config := Config{Distribution: DistributionStandalone}
+
+	// 3.12.7 is installed, and its installer is cached.
+	version, _ := NewVersion("3.12.7")
+	artifact, err := standaloneArtifact(config, version, runtime.GOARCH, testStandaloneRelease)
+	if err != nil {
+		t.Skip(err)
+	}
+	if err := os.WriteFile(filepath.Join(installerCacheDir, artifact.FileName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	executable := standaloneExecutablePath(filepath.Join(standaloneDir, version.String()))
+	if err := os.MkdirAll(filepath.Dir(executable), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(executable, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// the least recently used first.
+	now := time.Now()
+	installers := []cachedInstaller{
+		{name: "old.tgz", size: 100, modTime: now.Add(-60 * 24 * time.Hour)},
+		{name: artifact.FileName, size: 300, modTime: now.Add(-40 * 24 * time.Hour)},
+		{name: "middle.tgz", size: 200, modTime: now.Add(-10 * 24 * time.Hour)},
+		{name: "new.tgz", size: 400, modTime: now.Add(-time.Hour)},
+	}
+
+	tests := []struct {
+		name      string
+		options   CleanOptions
+		want      []string
+		wantTotal int64
+	}{
+		{name: "no filter", options: CleanOptions{MaxSize: -1}, want: []string{"old.tgz", artifact.FileName, "middle.tgz", "new.tgz"}, wantTotal: 0},
+		{name: "older than", options: CleanOptions{OlderThan: 30 * 24 * time.Hour, MaxSize: -1}, want: []string{"old.tgz", artifact.FileName}, wantTotal: 600},
+		{name: "max size", options: CleanOptions{MaxSize: 600}, want: []string{"old.tgz", artifact.FileName}, wantTotal: 600},
+		{name: "max size is enough", options: CleanOptions{MaxSize: 1000}, wantTotal: 1000},
+		{name: "max size zero", options: CleanOptions{MaxSize: 0}, want: []string{"old.tgz", artifact.FileName, "middle.tgz", "new.tgz"}, wantTotal: 0},
+		{name: "older than or max size", options: CleanOptions{OlderThan: 50 * 24 * time.Hour, MaxSize: 500}, want: []string{"old.tgz", artifact.FileName, "middle.tgz"}, wantTotal: 400},
+		{name: "keep installed", options: CleanOptions{KeepInstalled: true, MaxSize: -1}, want: []string{"old.tgz", "middle.tgz", "new.tgz"}, wantTotal: 300},
+		{name: "keep installed with max size", options: CleanOptions{KeepInstalled: true, MaxSize: 800}, want: []string{"old.tgz", "middle.tgz"}, wantTotal: 700},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed, total := selectInstallersToClean(config, tt.options, installers)
+			var got []string
+			for _, installer := range removed {
+				got = append(got, installer.name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || total != tt.wantTotal {
+				t.Errorf("selectInstallersToClean = %v, %d, want %v, %d", got, total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
@@ -25,18 +25,14 @@ SOFTWARE.
 package lib
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"github.com/hawk-tomy/pim/lib/list"
-	"io"
-	"log"
-	"net/http"
 	"sort"
-	"strings"
+	"time"
 )
 
 const (
-	BaseUrl                      = "https://api.github.com/repos/python/cpython/tags?per_page=100&page=%d"
 	supportedMinimumMinorVersion = 9
 )
 
@@ -44,7 +40,9 @@ var (
 	allVersions           []Version
 	fetchedVersions       map[int]*list.List[Version]
 	failedMinimumVersions map[int]Version
-	fetchedLatestVersions bool // guard against fetching more than once.
+	fetchedLatestVersions bool      // guard against fetching more than once.
+	fetchedProvider       string    // provider name of fetched versions.
+	fetchedAt             time.Time // when versions are fetched from provider. (saved as VersionCache.UpdateDate)
 )
 
 func init() {
@@ -53,100 +51,19 @@ func init() {
 	fetchedLatestVersions = false
 }
 
-type Tag struct {
-	Name   string `json:"name"`
-	Commit struct {
-		Sha string `json:"sha"`
-		Url string `json:"url"`
-	} `json:"commit"`
-	ZipballUrl string `json:"zipball_url"`
-	TarballUrl string `json:"tarball_url"`
-	NodeId     string `json:"node_id"`
-}
-
-func getVersionsByPage(config Config, page int) ([]Version, error) {
-	url := fmt.Sprintf(BaseUrl, page)
-
-	req, _ := http.NewRequest("GET", url, nil)
-	req.Header.Set("accept", "application/vnd.github+json")
-
-	client := new(http.Client)
-	resp, err := client.Do(req)
+func innerFetchLatestVersions(ctx context.Context, provider Provider) error {
+	versions, err := provider.ListVersions(ctx)
 	if err != nil {
-		return nil, err
-	}
-
-	defer deferErrCheck(resp.Body.Close)
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed call API")
-	}
-	byteArray, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var tags []Tag
-	err = json.Unmarshal(byteArray, &tags)
-	if err != nil {
-		return nil, err
-	}
-
-	var versions []Version
-	for _, tag := range tags {
-		// tag Name prefix is not v3, skip
-		// has prefix
-		if !strings.HasPrefix(tag.Name, "v3") {
-			continue
-		}
-		v, err := NewVersion(tag.Name)
-		if err != nil {
-			continue
-		}
-		versions = append(versions, v)
+		return err
 	}
 
-	return versions, nil
-}
-
-func innerFetchLatestVersions(config Config) error {
-	i := 1
-	fetchedVersions_ := make([]Version, 0)
-	isFetched := make(map[int]bool)
-	maxMinor := -1
-
-	for {
-		versions, err := getVersionsByPage(config, i)
-		if err != nil {
-			return err
-		}
-
-		fetchedVersions_ = append(fetchedVersions_, versions...)
-
-		// check if all minor versions from supportedMinimumMinorVersion to latestMinorVersion are fetched.
-		// GitHub API return tags sorted by name.
-		for _, v := range versions {
-			isFetched[v.Minor] = true
-			maxMinor = max(maxMinor, v.Minor)
-		}
-		flag := false
-		for i := supportedMinimumMinorVersion - 1; i <= maxMinor; i++ {
-			if _, ok := isFetched[i]; !ok {
-				flag = true
-			}
-		}
-		if !flag { // ok
-			break
-		}
-
-		// read next page
-		i++
-	}
-
-	saveVersions(fetchedVersions_)
+	saveVersions(versions, time.Now().UTC())
 	return nil
 }
 
-func saveVersions(versions []Version) {
+// saveVersions keeps versions fetched at fetched. (now for fetched from provider, or UpdateDate of the cache)
+func saveVersions(versions []Version, fetched time.Time) {
+	fetchedAt = fetched
 	sort.Slice(versions, func(i, j int) bool { return versions[i].LessThan(versions[j]) })
 	allVersions = versions
 	fetchVersionsEachMinor := make(map[int][]Version)
@@ -168,21 +85,29 @@ func saveVersions(versions []Version) {
 	}
 }
 
-func fetchLatestVersions(config Config) error {
+func fetchLatestVersions(ctx context.Context, config Config, provider Provider) error {
 	if fetchedLatestVersions {
 		return nil
 	}
+	fetchedProvider = provider.Name()
 
-	if need, err := readCache(); need { // if "need" is true, do not use cache. otherwise, not error.
-		if err := innerFetchLatestVersions(config); err != nil {
+	ttl, err := parseDuration(config.CacheTTL, defaultCacheTTL)
+	if err != nil {
+		return fmt.Errorf("invalid CacheTTL: %w", err)
+	}
+	if need, err := readCache(ctx, config, ttl); need { // if "need" is true, do not use cache.
+		if config.Offline {
+			return &OfflineError{fmt.Sprintf("version cache of %s provider", fetchedProvider)}
+		}
+		if err := innerFetchLatestVersions(ctx, provider); err != nil {
 			return err
 		}
 
-		if err := saveCache(); err != nil {
+		if err := saveCache(ctx); err != nil {
 			return err
 		}
 	} else if err != nil {
-		log.Fatal(err) // UNREACHABLE
+		return err
 	}
 
 	fetchedLatestVersions = true
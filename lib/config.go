@@ -36,6 +36,27 @@ type Config struct {
 	ForAllUser                 bool
 	TargetDirectory            string
 	AdditionalInstallerOptions map[string]string
+	Provider                   string
+	Distribution               string
+	PythonFtpBaseUrl           string
+	PythonApiBaseUrl           string
+	AllowUnverifiedDownloads   bool
+	Offline                    bool
+	CacheTTL                   string
+	CleanOlderThan             string
+	CleanMaxSize               string
+	CleanKeepInstalled         bool
+	RequireSigstore            bool
+	HttpConnectTimeout         string
+	HttpReadTimeout            string
+	HttpRetries                int
+	HttpProxy                  string
+	HttpCABundle               string
+	GitHubToken                string
+	DefaultPython              string
+	StandaloneBaseUrl          string
+	StandaloneRelease          string
+	StandaloneFlavor           string
 }
 
 var (
@@ -0,0 +1,180 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/hawk-tomy/pim/lib/list"
+	"net/http"
+	"runtime"
+)
+
+// Distribution is how python is installed.
+//   - DistributionNative: official installer on windows, build from source code on linux.
+//   - DistributionStandalone: prebuilt python-build-standalone archive. no admin rights or compiler is required.
+const (
+	DistributionNative     = "native"
+	DistributionStandalone = "standalone"
+)
+
+func distribution(config Config) (string, error) {
+	switch config.Distribution {
+	case "", DistributionNative:
+		return DistributionNative, nil
+	case DistributionStandalone:
+		return DistributionStandalone, nil
+	default:
+		return "", fmt.Errorf("unknown distribution: %s", config.Distribution)
+	}
+}
+
+// providerOf returns provider for target config. provider resolves artifacts of its own distribution,
+// so it is created again when target distribution is different from the one of config.
+func providerOf(target Config, config Config, provider Provider) (Provider, error) {
+	t, err := distribution(target)
+	if err != nil {
+		return nil, err
+	}
+	if d, err := distribution(config); err == nil && d == t {
+		return provider, nil
+	}
+	return NewProvider(target)
+}
+
+// downloadArtifact downloads the artifact of version for arch, and verifies it. (see verifyArtifact)
+func downloadArtifact(ctx context.Context, config Config, provider Provider, version Version, arch string) (Artifact, string, error) {
+	artifact, err := provider.ResolveArtifact(ctx, version, arch)
+	if err != nil {
+		return Artifact{}, "", err
+	}
+	// other pim process may download or verify the same file at the same time.
+	unlock, err := lockFile(ctx, artifact.FileName)
+	if err != nil {
+		return Artifact{}, "", err
+	}
+	defer deferErrCheck(unlock)
+
+	path, err := downloadFile(ctx, config, artifact.Url, artifact.FileName)
+	if err != nil {
+		if rErr := recordMissingArtifact(ctx, version, artifact, err); rErr != nil {
+			return Artifact{}, "", errors.Join(err, rErr)
+		}
+		return Artifact{}, "", err
+	}
+	return artifact, path, verifyArtifact(ctx, config, version, artifact, path)
+}
+
+// recordMissingArtifact records version as the oldest version without installer of the minor, if the installer is not found.
+// archive of python-build-standalone is only for the newest patch of each release, so missing archive is not recorded.
+func recordMissingArtifact(ctx context.Context, version Version, artifact Artifact, err error) error {
+	var sErr *StatusError
+	if artifact.Kind == ArtifactArchive || !errors.As(err, &sErr) || sErr.Status != http.StatusNotFound {
+		return nil
+	}
+	if v, ok := failedMinimumVersions[version.Minor]; !ok || v.GreaterThan(version) {
+		failedMinimumVersions[version.Minor] = version
+		return saveCache(ctx)
+	}
+	return nil
+}
+
+func installArtifact(ctx context.Context, config Config, version Version, artifact Artifact, path string) error {
+	if artifact.Kind == ArtifactArchive {
+		return extractStandalone(config, version, path, artifact.Root)
+	}
+	return installNative(ctx, config, version, artifact, path)
+}
+
+// doInstall installs the first installable version in candidates, and returns it.
+func doInstall(ctx context.Context, config Config, provider Provider, candidates []Version) (Version, error) {
+	for _, version := range candidates {
+		fmt.Fprintf(MessageOut(), "install: %s\n", version.String())
+		artifact, path, err := downloadArtifact(ctx, config, provider, version, runtime.GOARCH)
+		if err == nil {
+			return version, installArtifact(ctx, config, version, artifact, path)
+		}
+		var sErr *StatusError
+		if len(candidates) > 1 && errors.As(err, &sErr) {
+			continue
+		}
+		return Version{}, err
+	}
+	return Version{}, &NotFoundError{"can not found installable version"}
+}
+
+// doUpdate updates installation. new version is installed with the same arch, scope, distribution and directory.
+func doUpdate(ctx context.Context, config Config, provider Provider, installation Installation, version *list.Element[Version]) error {
+	key := installation.key()
+	target := installation.configOf(config)
+	provider, err := providerOf(target, config, provider)
+	if err != nil {
+		return err
+	}
+	config = target
+	var artifact Artifact
+	var path string
+	for {
+		artifact, path, err = downloadArtifact(ctx, config, provider, key.variantOf(version.Value), key.Arch)
+		if err == nil {
+			break
+		}
+		var sErr *StatusError
+		if errors.As(err, &sErr) {
+			version = version.Prev()
+			if version == nil {
+				return errors.New("can not found installable version. (not found installable version in checked version)")
+			}
+			if installation.Version.GreaterThanOrEqual(key.variantOf(version.Value)) {
+				// prev version is same as or older than already installed version.
+				return errors.New("can not found installable version. (not found installable version for newer then installed one.)")
+			}
+			continue
+		}
+		return err
+	}
+	if err := installArtifact(ctx, config, key.variantOf(version.Value), artifact, path); err != nil {
+		return err
+	}
+	if artifact.Kind == ArtifactArchive {
+		// archive is extracted into new directory. old one is not needed.
+		return removeOldStandalone(config, key.variantOf(version.Value))
+	}
+	return nil
+}
+
+func doUninstall(ctx context.Context, config Config, provider Provider, installation Installation) error {
+	target := installation.configOf(config)
+	provider, err := providerOf(target, config, provider)
+	if err != nil {
+		return err
+	}
+	config = target
+	if installation.Distribution == DistributionStandalone {
+		return uninstallStandalone(config, installation.Version)
+	}
+	return uninstallNative(ctx, config, provider, installation)
+}
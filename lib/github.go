@@ -0,0 +1,113 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GitHubPageCache is a cached response of GitHub API. it is reused when the API returns 304 Not Modified.
+type GitHubPageCache struct {
+	ETag     string    `json:"etag"`
+	Versions []Version `json:"versions"`
+}
+
+// RateLimitError is returned when GitHub API rate limit is exceeded.
+type RateLimitError struct {
+	Limit         int
+	Reset         time.Time
+	Authenticated bool
+}
+
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf(
+		"GitHub API rate limit exceeded (limit: %d). retry after %s (in %s)",
+		e.Limit,
+		e.Reset.Local().Format(time.DateTime),
+		time.Until(e.Reset).Round(time.Second),
+	)
+	if !e.Authenticated {
+		msg += ". set GITHUB_TOKEN or GitHubToken in config to raise the limit"
+	}
+	return msg
+}
+
+var (
+	githubPages map[int]GitHubPageCache
+)
+
+func init() {
+	githubPages = make(map[int]GitHubPageCache)
+}
+
+func githubToken(config Config) string {
+	if config.GitHubToken != "" {
+		return config.GitHubToken
+	}
+	return os.Getenv("GITHUB_TOKEN")
+}
+
+func newGitHubRequest(ctx context.Context, config Config, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("accept", "application/vnd.github+json")
+	if token := githubToken(config); token != "" {
+		req.Header.Set("authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// checkGitHubResponse returns RateLimitError if the rate limit is exceeded, and error if the status is not expected.
+func checkGitHubResponse(resp *http.Response, expected ...int) error {
+	authenticated := resp.Request != nil && resp.Request.Header.Get("authorization") != ""
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if resp.Header.Get("x-ratelimit-remaining") == "0" {
+			rErr := &RateLimitError{Authenticated: authenticated}
+			rErr.Limit, _ = strconv.Atoi(resp.Header.Get("x-ratelimit-limit"))
+			if reset, err := strconv.ParseInt(resp.Header.Get("x-ratelimit-reset"), 10, 64); err == nil {
+				rErr.Reset = time.Unix(reset, 0)
+			}
+			return rErr
+		}
+		// secondary rate limit
+		if retryAfter, err := strconv.Atoi(resp.Header.Get("retry-after")); err == nil {
+			return &RateLimitError{Reset: time.Now().Add(time.Duration(retryAfter) * time.Second), Authenticated: authenticated}
+		}
+	}
+	return fmt.Errorf("failed call API: %s", resp.Status)
+}
@@ -0,0 +1,216 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckGitHubResponse(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name          string
+		status        int
+		header        map[string]string
+		authenticated bool
+		// wantErr is "", "rate limit" or a part of the error message.
+		wantErr       string
+		wantLimit     int
+		wantReset     time.Time
+		wantRetryWait time.Duration
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "not modified", status: http.StatusNotModified},
+		{
+			name:   "rate limit exceeded",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"x-ratelimit-remaining": "0",
+				"x-ratelimit-limit":     "60",
+				"x-ratelimit-reset":     strconv.FormatInt(reset.Unix(), 10),
+			},
+			wantErr:   "rate limit",
+			wantLimit: 60,
+			wantReset: reset,
+		},
+		{
+			name:          "rate limit exceeded with token",
+			status:        http.StatusTooManyRequests,
+			header:        map[string]string{"x-ratelimit-remaining": "0", "x-ratelimit-limit": "5000"},
+			authenticated: true,
+			wantErr:       "rate limit",
+			wantLimit:     5000,
+		},
+		{
+			name:          "secondary rate limit",
+			status:        http.StatusForbidden,
+			header:        map[string]string{"retry-after": "60"},
+			wantErr:       "rate limit",
+			wantRetryWait: time.Minute,
+		},
+		{name: "forbidden", status: http.StatusForbidden, header: map[string]string{"x-ratelimit-remaining": "10"}, wantErr: "failed call API"},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: "failed call API"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "https://api.github.com/", nil)
+			if tt.authenticated {
+				req.Header.Set("authorization", "Bearer token")
+			}
+			resp := &http.Response{StatusCode: tt.status, Status: http.StatusText(tt.status), Header: http.Header{}, Request: req}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+
+			err := checkGitHubResponse(resp, http.StatusOK, http.StatusNotModified)
+			switch tt.wantErr {
+			case "":
+				if err != nil {
+					t.Fatalf("checkGitHubResponse: %v", err)
+				}
+			case "rate limit":
+				var rErr *RateLimitError
+				if !errors.As(err, &rErr) {
+					t.Fatalf("checkGitHubResponse = %v, want RateLimitError", err)
+				}
+				if rErr.Limit != tt.wantLimit || rErr.Authenticated != tt.authenticated {
+					t.Errorf("RateLimitError = %+v", rErr)
+				}
+				if !tt.wantReset.IsZero() && !rErr.Reset.Equal(tt.wantReset) {
+					t.Errorf("Reset = %s, want %s", rErr.Reset, tt.wantReset)
+				}
+				if tt.wantRetryWait != 0 && time.Until(rErr.Reset).Round(time.Minute) != tt.wantRetryWait {
+					t.Errorf("Reset = %s, want in %s", rErr.Reset, tt.wantRetryWait)
+				}
+				if strings.Contains(rErr.Error(), "GITHUB_TOKEN") == tt.authenticated {
+					t.Errorf("Error() = %s", rErr.Error())
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("checkGitHubResponse = %v, want %s", err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+// tagServer serves the tags of GitHub API with ETag.
+type tagServer struct {
+	etag        string
+	tags        []string
+	rateLimited bool
+	// requests are headers of received requests.
+	requests []http.Header
+}
+
+func (s *tagServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r.Header.Clone())
+	if s.rateLimited {
+		w.Header().Set("x-ratelimit-remaining", "0")
+		w.Header().Set("x-ratelimit-limit", "60")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("if-none-match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	tags := make([]Tag, len(s.tags))
+	for i, name := range s.tags {
+		tags[i] = Tag{Name: name}
+	}
+	w.Header().Set("etag", s.etag)
+	_ = json.NewEncoder(w).Encode(tags)
+}
+
+func TestGetVersionsByPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		cached      *GitHubPageCache
+		token       string
+		rateLimited bool
+		want        []string
+		wantErr     bool
+	}{
+		{name: "no cache", want: []string{"3.13.0", "3.12.7"}},
+		{name: "not modified", cached: &GitHubPageCache{ETag: `"v1"`, Versions: []Version{{Major: 3, Minor: 11, Micro: 9}}}, want: []string{"3.11.9"}},
+		{name: "modified", cached: &GitHubPageCache{ETag: `"v0"`, Versions: []Version{{Major: 3, Minor: 11, Micro: 9}}}, want: []string{"3.13.0", "3.12.7"}},
+		{name: "with token", token: "secret", want: []string{"3.13.0", "3.12.7"}},
+		{name: "rate limited", cached: &GitHubPageCache{ETag: `"v1"`}, rateLimited: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "")
+			resetVersions(t)
+			server := &tagServer{etag: `"v1"`, tags: []string{"v3.13.0", "v3.12.7", "v2.7.18", "legacy"}, rateLimited: tt.rateLimited}
+			useTestServer(t, server)
+			if tt.cached != nil {
+				githubPages[1] = *tt.cached
+			}
+
+			versions, err := getVersionsByPage(context.Background(), Config{GitHubToken: tt.token}, 1)
+			if tt.wantErr {
+				var rErr *RateLimitError
+				if !errors.As(err, &rErr) {
+					t.Fatalf("getVersionsByPage = %v, want RateLimitError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getVersionsByPage: %v", err)
+			}
+
+			got := make([]string, len(versions))
+			for i, v := range versions {
+				got[i] = v.getFullString()
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("getVersionsByPage = %v, want %v", got, tt.want)
+			}
+			if page := githubPages[1]; page.ETag != `"v1"` || len(page.Versions) != len(tt.want) {
+				t.Errorf("githubPages[1] = %+v", page)
+			}
+
+			header := server.requests[0]
+			if tt.cached != nil && header.Get("if-none-match") != tt.cached.ETag {
+				t.Errorf("if-none-match = %q, want %q", header.Get("if-none-match"), tt.cached.ETag)
+			} else if tt.cached == nil && header.Get("if-none-match") != "" {
+				t.Errorf("if-none-match = %q without cache", header.Get("if-none-match"))
+			}
+			if want := "Bearer " + tt.token; tt.token != "" && header.Get("authorization") != want {
+				t.Errorf("authorization = %q, want %q", header.Get("authorization"), want)
+			} else if tt.token == "" && header.Get("authorization") != "" {
+				t.Errorf("authorization = %q without token", header.Get("authorization"))
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// all HTTP requests are sent by the shared client built from config.
+//   - HttpConnectTimeout: timeout of connecting and TLS handshake. (e.g. "10s")
+//   - HttpReadTimeout: timeout of waiting the response, or the next data of the body. (e.g. "1m")
+//   - HttpRetries: retry count on network errors and 5xx, with exponential backoff. negative disables retry.
+//   - HttpProxy: proxy url. if empty, HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used.
+//   - HttpCABundle: PEM file of CA certificates, trusted in addition to system ones.
+const (
+	defaultConnectTimeout = 10 * time.Second
+	defaultReadTimeout    = 60 * time.Second
+	defaultRetries        = 3
+	retryBaseDelay        = time.Second
+)
+
+type httpClient struct {
+	client      *http.Client
+	readTimeout time.Duration
+	retries     int
+}
+
+// OfflineError is returned when resource is not available locally in offline mode.
+type OfflineError struct {
+	Resource string
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("offline: %s is not available locally. (import a bundle by \"pim bundle import\")", e.Resource)
+}
+
+var (
+	sharedClient     *httpClient
+	sharedClientErr  error
+	sharedClientOnce sync.Once
+)
+
+func parseDuration(value string, defaultValue time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func newHttpClient(config Config) (*httpClient, error) {
+	connectTimeout, err := parseDuration(config.HttpConnectTimeout, defaultConnectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HttpConnectTimeout: %w", err)
+	}
+	readTimeout, err := parseDuration(config.HttpReadTimeout, defaultReadTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HttpReadTimeout: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.ResponseHeaderTimeout = readTimeout
+
+	if config.HttpProxy != "" {
+		proxyUrl, err := url.Parse(config.HttpProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HttpProxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if config.HttpCABundle != "" {
+		pem, err := os.ReadFile(config.HttpCABundle)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate is found in HttpCABundle: %s", config.HttpCABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	retries := config.HttpRetries
+	if retries == 0 {
+		retries = defaultRetries
+	} else if retries < 0 {
+		retries = 0
+	}
+
+	return &httpClient{&http.Client{Transport: transport}, readTimeout, retries}, nil
+}
+
+func getHttpClient(config Config) (*httpClient, error) {
+	sharedClientOnce.Do(func() {
+		sharedClient, sharedClientErr = newHttpClient(config)
+	})
+	return sharedClient, sharedClientErr
+}
+
+// timeoutBody cancels the request if no data is read for timeout.
+type timeoutBody struct {
+	io.ReadCloser
+	timer   *time.Timer
+	timeout time.Duration
+	cancel  context.CancelFunc
+	expired atomic.Bool
+}
+
+func (b *timeoutBody) expire() {
+	b.expired.Store(true)
+	b.cancel()
+}
+
+func (b *timeoutBody) timeoutError() error {
+	return fmt.Errorf("read timeout: no data in %s", b.timeout)
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && b.expired.Load() {
+		return n, b.timeoutError()
+	}
+	b.timer.Reset(b.timeout)
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (c *httpClient) do(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	body := &timeoutBody{timeout: c.readTimeout, cancel: cancel}
+	body.timer = time.AfterFunc(c.readTimeout, body.expire)
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		body.timer.Stop()
+		cancel()
+		if body.expired.Load() {
+			return nil, body.timeoutError()
+		}
+		return nil, err
+	}
+	body.ReadCloser = resp.Body
+	resp.Body = body
+	return resp, nil
+}
+
+// waitRetry waits before the attempt-th retry. it returns error if ctx is done.
+func waitRetry(ctx context.Context, attempt int, reason string) error {
+	delay := retryBaseDelay << attempt
+	if WithVerbose > 0 {
+		fmt.Fprintf(os.Stderr, "retry in %s: %s\n", delay, reason)
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
+
+// httpDo sends req by the shared client. network errors and 5xx are retried. req must not have body.
+// in offline mode, req is never sent and OfflineError is returned.
+func httpDo(config Config, req *http.Request) (*http.Response, error) {
+	if config.Offline {
+		return nil, &OfflineError{req.URL.String()}
+	}
+	c, err := getHttpClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(req.Clone(ctx))
+		if err != nil && (ctx.Err() != nil || attempt >= c.retries) {
+			return nil, err
+		} else if err == nil && (resp.StatusCode < 500 || attempt >= c.retries) {
+			return resp, nil
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			deferErrCheck(resp.Body.Close)
+		}
+		if err := waitRetry(ctx, attempt, reason); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// httpGet sends GET request to url by the shared client.
+func httpGet(ctx context.Context, config Config, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return httpDo(config, req)
+}
+
+// isCanceled returns true if err is caused by canceling. (e.g. Ctrl-C)
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled)
+}
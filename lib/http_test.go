@@ -0,0 +1,236 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewHttpClient(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	caBundle := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notCert := filepath.Join(dir, "not-cert.pem")
+	if err := os.WriteFile(notCert, []byte("not a certificate\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		config          Config
+		wantErr         string
+		wantReadTimeout time.Duration
+		wantRetries     int
+	}{
+		{name: "default", wantReadTimeout: defaultReadTimeout, wantRetries: defaultRetries},
+		{name: "timeouts", config: Config{HttpConnectTimeout: "1s", HttpReadTimeout: "5m"}, wantReadTimeout: 5 * time.Minute, wantRetries: defaultRetries},
+		{name: "retries", config: Config{HttpRetries: 5}, wantReadTimeout: defaultReadTimeout, wantRetries: 5},
+		{name: "no retry", config: Config{HttpRetries: -1}, wantReadTimeout: defaultReadTimeout, wantRetries: 0},
+		{name: "proxy", config: Config{HttpProxy: "http://proxy.example:8080"}, wantReadTimeout: defaultReadTimeout, wantRetries: defaultRetries},
+		{name: "CA bundle", config: Config{HttpCABundle: caBundle}, wantReadTimeout: defaultReadTimeout, wantRetries: defaultRetries},
+		{name: "invalid connect timeout", config: Config{HttpConnectTimeout: "10"}, wantErr: "invalid HttpConnectTimeout"},
+		{name: "invalid read timeout", config: Config{HttpReadTimeout: "1 minute"}, wantErr: "invalid HttpReadTimeout"},
+		{name: "invalid proxy", config: Config{HttpProxy: "http://proxy.example:port"}, wantErr: "invalid HttpProxy"},
+		{name: "missing CA bundle", config: Config{HttpCABundle: filepath.Join(dir, "missing.pem")}, wantErr: "missing.pem"},
+		{name: "CA bundle without certificate", config: Config{HttpCABundle: notCert}, wantErr: "no certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newHttpClient(tt.config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("newHttpClient error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newHttpClient: %v", err)
+			}
+			if c.readTimeout != tt.wantReadTimeout || c.retries != tt.wantRetries {
+				t.Errorf("newHttpClient = {readTimeout: %s, retries: %d}, want {%s, %d}", c.readTimeout, c.retries, tt.wantReadTimeout, tt.wantRetries)
+			}
+			if tt.config.HttpCABundle == "" {
+				return
+			}
+			// the server certificate is trusted by CA bundle.
+			resp, err := c.client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("GET with CA bundle: %v", err)
+			}
+			deferErrCheck(resp.Body.Close)
+		})
+	}
+}
+
+func TestHttpDo(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		retries     int
+		readTimeout time.Duration
+		// handler responds to the attempt-th request. (0-origin)
+		handler    func(w http.ResponseWriter, r *http.Request, attempt int)
+		cancel     bool
+		wantStatus int
+		// wantErr is the error code (see classifyError) or a part of the error message.
+		wantErr      string
+		wantAttempts int32
+	}{
+		{
+			name:       "ok",
+			retries:    1,
+			handler:    func(w http.ResponseWriter, r *http.Request, attempt int) {},
+			wantStatus: http.StatusOK, wantAttempts: 1,
+		},
+		{
+			name:    "retry 5xx",
+			retries: 1,
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				if attempt == 0 {
+					w.WriteHeader(http.StatusBadGateway)
+				}
+			},
+			wantStatus: http.StatusOK, wantAttempts: 2,
+		},
+		{
+			name:    "5xx after retries",
+			retries: 0,
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantStatus: http.StatusServiceUnavailable, wantAttempts: 1,
+		},
+		{
+			name:    "4xx is not retried",
+			retries: 1,
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantStatus: http.StatusNotFound, wantAttempts: 1,
+		},
+		{
+			name:        "read timeout",
+			readTimeout: 50 * time.Millisecond,
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+			},
+			wantErr: "read timeout", wantAttempts: 1,
+		},
+		{
+			name:    "offline",
+			config:  Config{Offline: true},
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {},
+			wantErr: "offline", wantAttempts: 0,
+		},
+		{
+			name:    "canceled",
+			retries: 1,
+			handler: func(w http.ResponseWriter, r *http.Request, attempt int) {},
+			cancel:  true,
+			wantErr: "canceled", wantAttempts: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(w, r, int(attempts.Add(1))-1)
+			}))
+			defer server.Close()
+			readTimeout := tt.readTimeout
+			if readTimeout == 0 {
+				readTimeout = 5 * time.Second
+			}
+			useHttpClient(t, &httpClient{server.Client(), readTimeout, tt.retries})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			resp, err := httpGet(ctx, tt.config, server.URL)
+			if err == nil {
+				deferErrCheck(resp.Body.Close)
+			}
+			if tt.wantErr != "" {
+				if code, _ := classifyError(err); err == nil || code != tt.wantErr && !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("httpDo error = %v, want %s", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("httpDo: %v", err)
+			} else if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if n := attempts.Load(); n != tt.wantAttempts {
+				t.Errorf("requests = %d, want %d", n, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestTimeoutBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the header is sent, but the body stalls.
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	useHttpClient(t, &httpClient{server.Client(), 50 * time.Millisecond, 0})
+
+	resp, err := httpGet(context.Background(), Config{}, server.URL)
+	if err != nil {
+		t.Fatalf("httpGet: %v", err)
+	}
+	defer deferErrCheck(resp.Body.Close)
+	b := make([]byte, 64)
+	var readErr error
+	for readErr == nil {
+		_, readErr = resp.Body.Read(b)
+	}
+	if !strings.Contains(readErr.Error(), "read timeout") {
+		t.Errorf("Read error = %v, want read timeout", readErr)
+	}
+}
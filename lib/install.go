@@ -25,43 +25,69 @@ SOFTWARE.
 package lib
 
 import (
-	"errors"
-	"fmt"
+	"context"
+	"sort"
 )
 
-func InstallPython(config Config, version Version, needLatest bool) error {
-	if err := fetchLatestVersions(config); err != nil {
-		return err
-	}
+const (
+	freeThreadedMinimumMinorVersion = 13
+)
+
+// findMatchingVersions returns versions matching spec, newest first.
+// pre-releases are excluded unless allowed by config or spec.
+// versions known to have no installer are excluded, unless spec pins the version.
+// if spec selects free-threaded build, returned versions are free-threaded.
+func findMatchingVersions(config Config, spec Specifier) []Version {
+	allowPreRelease := config.AllowPreRelease || spec.AllowsPreRelease()
+	freeThreaded := spec.FreeThreaded()
 
-	if needLatest {
+	var versions []Version
+	for minor, versionList := range fetchedVersions {
 		var v_ *Version
-		if v__, ok_ := failedMinimumVersions[version.Minor]; ok_ {
+		if v__, ok_ := failedMinimumVersions[minor]; ok_ && !spec.IsExact() {
 			v_ = &v__
 		} else {
 			v_ = nil
 		}
-		v := fetchedVersions[version.Minor].Back()
-		for v != nil {
+		for v := versionList.Front(); v != nil; v = v.Next() {
 			isFailedVer := v_ != nil && v_.LessThanOrEqual(v.Value)
-			isAllowedVer := config.AllowPreRelease || v.Value.Pre == 0
-			if !isFailedVer && isAllowedVer {
-				fmt.Printf("install: %s\n", v.Value.String())
-				return doInstall(config, v, needLatest)
+			isAllowedVer := allowPreRelease || !v.Value.IsPreRelease()
+			if isFailedVer || !isAllowedVer || !spec.Match(v.Value) {
+				continue
+			}
+			if freeThreaded {
+				if v.Value.Minor < freeThreadedMinimumMinorVersion {
+					continue
+				}
+				ver := v.Value
+				ver.FreeThreaded = true
+				versions = append(versions, ver)
+			} else {
+				versions = append(versions, v.Value)
 			}
-			v = v.Prev()
 		}
-		return fmt.Errorf("not found latest version")
 	}
 
-	if versions, ok := fetchedVersions[version.Minor]; ok {
-		v := versions.Front()
-		for v != nil {
-			if v.Value.Equal(version) {
-				return doInstall(config, v, needLatest)
-			}
-			v = v.Next()
+	sort.Slice(versions, func(i, j int) bool { return versions[i].GreaterThan(versions[j]) })
+	return versions
+}
+
+// InstallPython installs the newest installable version matching spec, and returns the installed version.
+func InstallPython(ctx context.Context, config Config, provider Provider, spec Specifier) (Version, error) {
+	if err := fetchLatestVersions(ctx, config, provider); err != nil {
+		return Version{}, err
+	}
+
+	versions := findMatchingVersions(config, spec)
+	if len(versions) == 0 {
+		if spec.IsExact() {
+			return Version{}, &NotFoundError{"the version is not found"}
 		}
+		return Version{}, &NotFoundError{"not found latest version"}
+	}
+	version, err := doInstall(ctx, config, provider, versions)
+	if err == nil {
+		pruneByRetention(ctx, config)
 	}
-	return errors.New("the version is not found")
+	return version, err
 }
@@ -0,0 +1,160 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// scope of installation.
+//   - ScopeAllUser: installed for all users (HKLM on windows).
+//   - ScopePerUser: installed for current user (HKCU on windows).
+const (
+	ScopeAllUser = "all-user"
+	ScopePerUser = "per-user"
+)
+
+// PEP 514 company of installation. pim manages (updates and uninstalls) only these companies provided by pim.
+// other companies (e.g. "ContinuumAnalytics" of Anaconda) are only shown.
+const (
+	CompanyPythonCore = "PythonCore"
+	CompanyStandalone = "python-build-standalone"
+)
+
+// Installation is an installed python.
+type Installation struct {
+	Version        Version
+	DisplayName    string
+	Company        string
+	DirectoryPath  string
+	ExecutablePath string
+	// Tag is PEP 514 tag. (e.g. "3.13t", "Anaconda3-64") empty if not registered.
+	Tag string
+	// Registry is "HKLM" or "HKCU" where the installation is registered. empty if not registered (e.g. linux).
+	Registry     string
+	Scope        string
+	Arch         string
+	Distribution string
+}
+
+// Managed reports whether pim can update and uninstall the installation.
+func (i Installation) Managed() bool {
+	// python from microsoft store is registered as PythonCore, but installed by the store.
+	if strings.Contains(strings.ToLower(i.DirectoryPath), `\windowsapps\`) {
+		return false
+	}
+	return i.Company == CompanyPythonCore || i.Company == CompanyStandalone
+}
+
+// MatchCompany reports whether the installation is of company. (case-insensitive, empty matches all)
+func (i Installation) MatchCompany(company string) bool {
+	return company == "" || strings.EqualFold(i.Company, company)
+}
+
+// CachedInstaller returns the path of installer (or archive) cached for the installation.
+func (i Installation) CachedInstaller(config Config) (string, bool) {
+	if i.Distribution == DistributionStandalone {
+		triple, err := standaloneTriple(i.Arch)
+		if err != nil {
+			return "", false
+		}
+		pattern := fmt.Sprintf(standaloneFileNameBase, i.Version.getFullString(), "*", triple, "*")
+		paths, _ := filepath.Glob(filepath.Join(installerCacheDir, pattern))
+		for _, path := range paths {
+			if strings.Contains(filepath.Base(path), "freethreaded") == i.Version.FreeThreaded {
+				return path, true
+			}
+		}
+		return "", false
+	}
+
+	artifact, err := nativeArtifact(config, i.Version, i.Arch)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(installerCacheDir, artifact.FileName)
+	return path, isFile(path)
+}
+
+func getInstallations(config Config) []Installation {
+	return append(getNativeInstallations(config), getStandaloneInstallations(config)...)
+}
+
+func getPythonVersions(config Config) []Version {
+	var versions []Version
+	for _, installation := range getInstallations(config) {
+		versions = append(versions, installation.Version)
+	}
+	return versions
+}
+
+// FindInstallation returns the newest installation which matches spec.
+// installations of pim are searched first, because finding others runs every python in PATH. (it is slow for shims)
+func FindInstallation(config Config, spec Specifier) (Installation, error) {
+	if installation, ok := newestInstallation(append(getPimInstallations(config), getStandaloneInstallations(config)...), spec); ok {
+		return installation, nil
+	}
+	if installation, ok := newestInstallation(getInstallations(config), spec); ok {
+		return installation, nil
+	}
+	return Installation{}, &NotFoundError{fmt.Sprintf("not found installed python: %s", spec.String())}
+}
+
+func newestInstallation(installations []Installation, spec Specifier) (Installation, bool) {
+	var found []Installation
+	for _, installation := range installations {
+		if installation.Version.FreeThreaded == spec.FreeThreaded() && spec.Match(installation.Version) {
+			found = append(found, installation)
+		}
+	}
+	if len(found) == 0 {
+		return Installation{}, false
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Version.GreaterThan(found[j].Version)
+	})
+	return found[0], true
+}
+
+func readVersionFromExecutable(path string) (Version, error) {
+	cmd := exec.Command(path, "--version")
+	cmd.Env = environWithoutShims()
+	out, err := cmd.Output()
+	if err != nil {
+		return Version{}, err
+	}
+	// output is like "Python 3.12.0"
+	return NewVersion(strings.TrimPrefix(strings.TrimSpace(string(out)), "Python "))
+}
+
+func isUnder(path string, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
@@ -0,0 +1,165 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	partSuffix = ".part"
+)
+
+var (
+	installerCacheDir string
+	dataDir           string
+)
+
+type StatusError struct {
+	Status int
+}
+
+func (e *StatusError) Error() string { return fmt.Sprintf("Bad Status: %d", e.Status) }
+
+func init() {
+	installerCacheDir = filepath.Join(cacheDir, "installer")
+	if _, err := os.Stat(installerCacheDir); os.IsNotExist(err) {
+		err := os.MkdirAll(installerCacheDir, 0755)
+		cobra.CheckErr(err)
+	}
+
+	home, err := os.UserHomeDir()
+	cobra.CheckErr(err)
+
+	// python installed by pim (not by official installer) is placed here.
+	dataDir = filepath.Join(home, ".local", "share", "pim")
+}
+
+// downloadFile downloads url into installerCacheDir as fileName and returns the path.
+// already downloaded file is reused. if the server does not have the file, version is recorded as failed.
+// file is downloaded into "<fileName>.part" and renamed on success, so interrupted download is never reused.
+// partial file is resumed by Range request. interrupted download is retried (and resumed) as HttpRetries.
+func downloadFile(ctx context.Context, config Config, url string, fileName string) (string, error) {
+	filePath := filepath.Join(installerCacheDir, fileName)
+
+	if _, err := os.Stat(filePath); err == nil {
+		// mtime is used as the last used time by "pim clean --older-than".
+		now := time.Now()
+		_ = os.Chtimes(filePath, now, now)
+		return filePath, nil
+	} else if config.Offline {
+		return "", &OfflineError{fileName}
+	}
+
+	c, err := getHttpClient(config)
+	if err != nil {
+		return "", err
+	}
+	for attempt := 0; ; attempt++ {
+		err := downloadPart(ctx, config, url, filePath)
+		var sErr *StatusError
+		if err == nil {
+			return filePath, nil
+		} else if ctx.Err() != nil || errors.As(err, &sErr) || attempt >= c.retries {
+			return "", err
+		}
+		if err := waitRetry(ctx, attempt, err.Error()); err != nil {
+			return "", err
+		}
+	}
+}
+
+// downloadPart downloads url into "<filePath>.part", resuming it if exists, and renames it to filePath.
+func downloadPart(ctx context.Context, config Config, url string, filePath string) error {
+	partPath := filePath + partSuffix
+	var offset int64
+	if info, err := os.Stat(partPath); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := httpDo(config, req)
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(resp.Body.Close)
+
+	flag := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range is like "bytes 100-199/200"
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return fmt.Errorf("unexpected Content-Range: %s", resp.Header.Get("Content-Range"))
+		}
+	case http.StatusOK:
+		// server does not support Range request. download from the beginning.
+		offset = 0
+		flag |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// partial file is broken. download again.
+		if err := os.Remove(partPath); err != nil {
+			return err
+		}
+		return downloadPart(ctx, config, url, filePath)
+	default:
+		return &StatusError{resp.StatusCode}
+	}
+
+	out, err := os.OpenFile(partPath, flag, 0644)
+	if err != nil {
+		return err
+	}
+
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	p := newProgress(filepath.Base(filePath), offset, total)
+	_, err = io.Copy(out, io.TeeReader(resp.Body, p))
+	p.Done()
+	if cErr := out.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(partPath, filePath)
+}
@@ -0,0 +1,215 @@
+//go:build linux
+
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// example (python 3.11.0)
+// pre-releases: a/b/rc/(final)
+// - alpha: https://www.python.org/ftp/python/3.11.0/Python-3.11.0a1.tgz
+// - beta: https://www.python.org/ftp/python/3.11.0/Python-3.11.0b1.tgz
+// - rc: https://www.python.org/ftp/python/3.11.0/Python-3.11.0rc1.tgz
+// - final: https://www.python.org/ftp/python/3.11.0/Python-3.11.0.tgz
+const (
+	sourceUrlBase      = `%s/%s/Python-%s.tgz`
+	sourceFileNameBase = `Python-%s.tgz`
+	allUserInstallDir  = "/opt/pim/python"
+	// prefixMarkerName is the file written into the prefix installed by pim. only the prefix with it is removed.
+	prefixMarkerName = ".pim-installation"
+)
+
+var (
+	userInstallDir string
+	// nativeArtifactRegex matches file name of nativeArtifact. the first group is version.
+	nativeArtifactRegex = regexp.MustCompile(`^Python-(.+)\.tgz$`)
+)
+
+func init() {
+	userInstallDir = filepath.Join(dataDir, "python")
+}
+
+func nativeArtifact(config Config, version Version, arch string) (Artifact, error) {
+	dirVersionString := version.getStringWithoutPre()
+	fileVersionString := version.getFullString()
+
+	return Artifact{
+		Kind:         ArtifactSource,
+		Url:          fmt.Sprintf(sourceUrlBase, pythonFtpBaseUrl(config), dirVersionString, fileVersionString),
+		FileName:     fmt.Sprintf(sourceFileNameBase, fileVersionString),
+		Verification: VerifyPythonOrg,
+	}, nil
+}
+
+// installPrefix returns the prefix python is installed into.
+// each minor version has own prefix, like "~/.local/share/pim/python/3.12".
+// free-threaded build has own prefix too, like "~/.local/share/pim/python/3.13t".
+// with TargetDirectory, the prefix is in it too, like "<TargetDirectory>/3.12".
+func installPrefix(config Config, version Version) string {
+	root := userInstallDir
+	if config.TargetDirectory != "" {
+		root = config.TargetDirectory
+	} else if config.ForAllUser {
+		root = allUserInstallDir
+	}
+	return filepath.Join(root, version.getMinorString())
+}
+
+// installationTargetDirectory returns TargetDirectory to install into the directory of installation again.
+// both prefix and directory of python-build-standalone are in TargetDirectory. (see installPrefix and standaloneRoot)
+func installationTargetDirectory(installation Installation) string {
+	if installation.DirectoryPath == "" {
+		return ""
+	}
+	return filepath.Dir(installation.DirectoryPath)
+}
+
+// hasPrefixMarker returns true if prefix is installed by pim. (see prefixMarkerName)
+func hasPrefixMarker(prefix string) bool {
+	return isFile(filepath.Join(prefix, prefixMarkerName))
+}
+
+// preparePrefix creates prefix, and returns true if it is new. existing prefix is reused only if it is installed by pim,
+// not to overwrite or remove files of others later.
+func preparePrefix(prefix string) (bool, error) {
+	entries, err := os.ReadDir(prefix)
+	if err == nil && len(entries) > 0 {
+		if !hasPrefixMarker(prefix) {
+			return false, fmt.Errorf("%s is not empty and not installed by pim", prefix)
+		}
+		return false, nil
+	}
+	return true, os.MkdirAll(prefix, 0755)
+}
+
+// writePrefixMarker marks prefix as installed by pim. it is written after the build is done,
+// so broken prefix of failed build is not treated as installed.
+func writePrefixMarker(prefix string, version Version) error {
+	return os.WriteFile(filepath.Join(prefix, prefixMarkerName), []byte(version.String()+"\n"), 0644)
+}
+
+func callBuildStep(ctx context.Context, dir string, path string, args ...string) error {
+	if WithVerbose > 0 {
+		fmt.Fprintf(MessageOut(), "call: %s %s\n", path, strings.Join(args, " "))
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Dir = dir
+
+	var stdout strings.Builder
+	cmd.Stdout = &stdout
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to call %s: %w\nstdout: %s\nstderr: %s", path, err, stdout.String(), stderr.String())
+	}
+	return nil
+}
+
+func buildConfigureArgument(config Config, version Version, prefix string) []string {
+	var args = []string{
+		fmt.Sprintf("--prefix=%s", prefix),
+	}
+
+	if version.FreeThreaded {
+		args = append(args, "--disable-gil")
+	}
+
+	for k, v := range config.AdditionalInstallerOptions {
+		if v == "" {
+			args = append(args, fmt.Sprintf("--%s", k))
+		} else {
+			args = append(args, fmt.Sprintf("--%s=%s", k, v))
+		}
+	}
+
+	return args
+}
+
+func buildFromSource(ctx context.Context, config Config, version Version, path string) error {
+	workDir, err := os.MkdirTemp("", "pim-build-")
+	if err != nil {
+		return err
+	}
+	defer deferErrCheck(func() error { return os.RemoveAll(workDir) })
+
+	if err := extractArchive(path, workDir, ""); err != nil {
+		return err
+	}
+	srcDir := filepath.Join(workDir, "Python-"+version.getFullString())
+
+	prefix := installPrefix(config, version)
+	isNew, err := preparePrefix(prefix)
+	if err != nil {
+		return err
+	}
+	if err := buildInto(ctx, config, version, srcDir, prefix); err != nil {
+		if isNew {
+			// remove half-built prefix. (e.g. failed or canceled "make install")
+			deferErrCheck(func() error { return os.RemoveAll(prefix) })
+		}
+		return err
+	}
+	return writePrefixMarker(prefix, version)
+}
+
+// buildInto builds python in srcDir, and installs it into prefix.
+func buildInto(ctx context.Context, config Config, version Version, srcDir string, prefix string) error {
+	if err := callBuildStep(ctx, srcDir, "./configure", buildConfigureArgument(config, version, prefix)...); err != nil {
+		return err
+	}
+	if err := callBuildStep(ctx, srcDir, "make", "-j", strconv.Itoa(runtime.NumCPU())); err != nil {
+		return err
+	}
+	return callBuildStep(ctx, srcDir, "make", "install")
+}
+
+func installNative(ctx context.Context, config Config, version Version, artifact Artifact, path string) error {
+	if artifact.Kind != ArtifactSource {
+		return fmt.Errorf("unsupported artifact on linux: %s", artifact.FileName)
+	}
+	return buildFromSource(ctx, config, version, path)
+}
+
+// uninstallNative removes the prefix of installation. the prefix is removed only if it has the marker.
+func uninstallNative(ctx context.Context, config Config, provider Provider, installation Installation) error {
+	prefix := installation.DirectoryPath
+	// do not remove directory which is not installed by pim.
+	if prefix == "" || !hasPrefixMarker(prefix) {
+		return fmt.Errorf("python %s is not installed by pim: %s", installation.Version.String(), prefix)
+	}
+	return os.RemoveAll(prefix)
+}
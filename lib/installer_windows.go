@@ -27,88 +27,76 @@ SOFTWARE.
 package lib
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"github.com/hawk-tomy/pim/lib/list"
-	"io"
-	"net/http"
-	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
-
-	"github.com/spf13/cobra"
 )
 
 // example (python 3.11.0)
-// arch: amd64/arm64
+// arch: amd64/arm64/386 (32bit installer has no arch suffix)
 // - amd64: https://www.python.org/ftp/python/3.11.0/python-3.11.0-amd64.exe
 // - arm64: https://www.python.org/ftp/python/3.11.0/python-3.11.0-arm64.exe
+// - 386: https://www.python.org/ftp/python/3.11.0/python-3.11.0.exe
 // pre-releases: a/b/rc/(final)
 // - alpha: https://www.python.org/ftp/python/3.11.0/python-3.11.0a1-amd64.exe
 // - beta: https://www.python.org/ftp/python/3.11.0/python-3.11.0b1-amd64.exe
 // - rc: https://www.python.org/ftp/python/3.11.0/python-3.11.0rc1-amd64.exe
 // - final: https://www.python.org/ftp/python/3.11.0/python-3.11.0-amd64.exe
 const (
-	downloadUrlBase = `https://www.python.org/ftp/python/%s/python-%s-%s.exe`
-	fileNameBase    = `python-%s-%s.exe`
-)
-
-var (
-	installerCacheDir string
+	downloadUrlBase = `%s/%s/%s`
+	fileNameBase    = `python-%s%s.exe`
 )
 
-type StatusError struct {
-	Status int
+// archSuffix returns the suffix of installer file name for arch.
+func archSuffix(arch string) string {
+	switch arch {
+	case "arm64":
+		return "-arm64"
+	case "386":
+		return ""
+	}
+	return "-amd64"
 }
 
-func (e *StatusError) Error() string { return fmt.Sprintf("Bad Status: %d", e.Status) }
+var (
+	// nativeArtifactRegex matches file name of nativeArtifact for current arch. the first group is version.
+	nativeArtifactRegex = newNativeArtifactRegex(runtime.GOARCH)
+)
 
-func init() {
-	installerCacheDir = filepath.Join(cacheDir, "installer")
-	if _, err := os.Stat(installerCacheDir); os.IsNotExist(err) {
-		err := os.MkdirAll(installerCacheDir, 0755)
-		cobra.CheckErr(err)
+func newNativeArtifactRegex(arch string) *regexp.Regexp {
+	if suffix := archSuffix(arch); suffix != "" {
+		return regexp.MustCompile(fmt.Sprintf(`^python-(.+)%s\.exe$`, regexp.QuoteMeta(suffix)))
 	}
+	// version does not contain "-".
+	return regexp.MustCompile(`^python-([^-]+)\.exe$`)
 }
 
-func downloadInstaller(version Version) (string, error) {
+func nativeArtifact(config Config, version Version, arch string) (Artifact, error) {
 	dirVersionString := version.getStringWithoutPre()
-	fileVersionString := version.getFullString()
-	arch := "amd64"
-	if runtime.GOARCH == "arm64" {
-		arch = "arm64"
-	}
-
-	url := fmt.Sprintf(downloadUrlBase, dirVersionString, fileVersionString, arch)
-	filePath := filepath.Join(installerCacheDir, fmt.Sprintf(fileNameBase, fileVersionString, arch))
-
-	if _, err := os.Stat(filePath); err == nil {
-		return filePath, nil
-	}
+	fileName := fmt.Sprintf(fileNameBase, version.getFullString(), archSuffix(arch))
+
+	return Artifact{
+		Kind:         ArtifactInstaller,
+		Url:          fmt.Sprintf(downloadUrlBase, pythonFtpBaseUrl(config), dirVersionString, fileName),
+		FileName:     fileName,
+		Verification: VerifyPythonOrg,
+	}, nil
+}
 
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-	if resp.StatusCode != http.StatusOK {
-		if v, ok := failedMinimumVersions[version.Minor]; !ok || v.GreaterThan(version) {
-			failedMinimumVersions[version.Minor] = version
-			cobra.CheckErr(saveCache())
-		}
-		return "", &StatusError{resp.StatusCode}
+// installationTargetDirectory returns TargetDirectory to install into the directory of installation again.
+// the installer is installed into TargetDirectory itself, python-build-standalone is installed into its sub directory.
+func installationTargetDirectory(installation Installation) string {
+	if installation.DirectoryPath == "" {
+		return ""
 	}
-	defer deferErrCheck(resp.Body.Close)
-
-	out, err := os.Create(filePath)
-	if err != nil {
-		return "", err
+	if installation.Distribution == DistributionStandalone {
+		return filepath.Dir(installation.DirectoryPath)
 	}
-	defer deferErrCheck(out.Close)
-
-	_, err = io.Copy(out, resp.Body)
-	return filePath, err
+	return filepath.Clean(installation.DirectoryPath)
 }
 
 func boolToInt(b bool) int {
@@ -140,9 +128,13 @@ func buildInstallerArgument(config Config, additionalCmd ...string) []string {
 	return args
 }
 
-func callInstaller(path string, args ...string) error {
+// callInstaller runs the installer. it is not killed by canceling ctx, because interrupted installer breaks the installation.
+func callInstaller(ctx context.Context, path string, args ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if WithVerbose > 0 {
-		fmt.Printf("call: %s %s\n", path, strings.Join(args, " "))
+		fmt.Fprintf(MessageOut(), "call: %s %s\n", path, strings.Join(args, " "))
 	}
 	cmd := exec.Command(path, args...)
 
@@ -157,57 +149,28 @@ func callInstaller(path string, args ...string) error {
 	return nil
 }
 
-func doInstall(config Config, version *list.Element[Version], needLatest bool) error {
-	var path string
-	var err error
-	for {
-		path, err = downloadInstaller(version.Value)
-		if err == nil {
-			break
-		}
-		var sErr *StatusError
-		if needLatest && errors.As(err, &sErr) {
-			version = version.Prev()
-			if version == nil {
-				return errors.New("can not found installable version")
-			}
-			continue
-		}
-		return err
+func installNative(ctx context.Context, config Config, version Version, artifact Artifact, path string) error {
+	if artifact.Kind != ArtifactInstaller {
+		return fmt.Errorf("unsupported artifact on windows: %s", artifact.FileName)
 	}
-	return callInstaller(path, buildInstallerArgument(config)...)
-}
-
-func doUpdate(config Config, version *list.Element[Version]) error {
-	var path string
-	var err error
-	for {
-		path, err = downloadInstaller(version.Value)
-		if err == nil {
-			break
-		}
-		var sErr *StatusError
-		if errors.As(err, &sErr) {
-			version = version.Prev()
-			if version == nil {
-				return errors.New("can not found installable version. (not found installable version in checked version)")
-			}
-			if v, ok := installedPythonVersions[version.Value.Minor]; !ok || v.GreaterThanOrEqual(version.Value) {
-				// not ok -> UNREACHABLE (check for assert) -> return error
-				// v >= version -> prev version is same as or older than already installed version.
-				return errors.New("can not found installable version. (not found installable version for newer then installed one.)")
-			}
-			continue
-		}
-		return err
+	var options []string
+	if version.FreeThreaded {
+		options = append(options, "Include_freethreaded=1")
 	}
-	return callInstaller(path, buildInstallerArgument(config)...)
+	return callInstaller(ctx, path, buildInstallerArgument(config, options...)...)
 }
 
-func doUninstall(config Config, version Version) error {
-	path, err := downloadInstaller(version)
+// uninstallNative runs the installer with uninstall options.
+// the installer is downloaded only if neither the cached one nor the registered one is found.
+func uninstallNative(ctx context.Context, config Config, provider Provider, installation Installation) error {
+	version := installation.Version
+	path, err := findUninstaller(ctx, systemUninstallerSource{config, provider}, version, installation.Arch)
 	if err != nil {
 		return err
 	}
-	return callInstaller(path, buildInstallerArgument(config, "/uninstall")...)
+	if version.FreeThreaded {
+		// free-threaded build is an optional feature of the installer. remove only the feature.
+		return callInstaller(ctx, path, buildInstallerArgument(config, "/modify", "Include_freethreaded=0")...)
+	}
+	return callInstaller(ctx, path, buildInstallerArgument(config, "/uninstall")...)
 }
@@ -0,0 +1,100 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockRetryDelay = 100 * time.Millisecond
+	cacheLockName  = "cache.json"
+)
+
+// lockFile acquires the exclusive advisory lock named name, shared with other pim processes.
+// if the lock is held by other process, it waits until released or ctx is canceled.
+// returned function releases the lock.
+func lockFile(ctx context.Context, name string) (func() error, error) {
+	// lock files are not in installerCacheDir, not to be treated as installers.
+	lockDir := filepath.Join(cacheDir, "lock")
+	if err := os.MkdirAll(lockDir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(lockDir, name+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	for waiting := false; ; waiting = true {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			deferErrCheck(f.Close)
+			return nil, err
+		} else if locked {
+			break
+		}
+		if !waiting {
+			fmt.Fprintf(MessageOut(), "waiting for other pim process: %s\n", name)
+		}
+		select {
+		case <-ctx.Done():
+			deferErrCheck(f.Close)
+			return nil, ctx.Err()
+		case <-time.After(lockRetryDelay):
+		}
+	}
+
+	return func() error {
+		if err := unlockFile(f); err != nil {
+			deferErrCheck(f.Close)
+			return err
+		}
+		return f.Close()
+	}, nil
+}
+
+// writeFileAtomic writes data into the temporary file in the same directory, and renames it to path.
+// readers never see partially written file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		deferErrCheck(func() error { return os.Remove(f.Name()) })
+	}
+	return err
+}
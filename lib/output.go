@@ -0,0 +1,293 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// output format of commands.
+//   - OutputText: message for human.
+//   - OutputJSON, OutputYAML: structured document described by Schema. messages for human are written to stderr.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// SchemaVersion is the version of structured documents. it is incremented on incompatible change.
+const SchemaVersion = 1
+
+// exit codes of pim.
+const (
+	ExitError     = 1
+	ExitUsage     = 2
+	ExitNotFound  = 3
+	ExitDownload  = 4
+	ExitRateLimit = 5
+	ExitVerify    = 6
+	ExitOffline   = 7
+	// same as shells report for SIGINT.
+	ExitCanceled = 130
+)
+
+var (
+	OutputFormat = OutputText
+	// Schema is JSON Schema of structured documents.
+	//go:embed schema.json
+	Schema string
+)
+
+// NotFoundError is returned when requested python (or version) is not found.
+type NotFoundError struct {
+	Message string
+}
+
+func (e *NotFoundError) Error() string { return e.Message }
+
+// UsageError is returned when command line is invalid.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string { return e.Err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// SetOutputFormat validates and sets OutputFormat.
+func SetOutputFormat(format string) error {
+	switch format {
+	case OutputText, OutputJSON, OutputYAML:
+	default:
+		return &UsageError{fmt.Errorf("unknown output format: %s (available: text, json, yaml)", format)}
+	}
+	OutputFormat = format
+	return nil
+}
+
+func IsStructuredOutput() bool {
+	return OutputFormat == OutputJSON || OutputFormat == OutputYAML
+}
+
+// MessageOut returns the writer of messages for human.
+// it is stderr for structured format, so that stdout has only the document.
+func MessageOut() io.Writer {
+	if IsStructuredOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// Header is the common part of structured documents. Kind tells which document it is.
+type Header struct {
+	SchemaVersion int    `json:"schema_version" yaml:"schema_version"`
+	Kind          string `json:"kind" yaml:"kind"`
+}
+
+func newHeader(kind string) Header {
+	return Header{SchemaVersion, kind}
+}
+
+// InstallationDocument is Installation in structured documents.
+type InstallationDocument struct {
+	Version        string `json:"version" yaml:"version"`
+	Minor          string `json:"minor" yaml:"minor"`
+	FreeThreaded   bool   `json:"free_threaded" yaml:"free_threaded"`
+	DisplayName    string `json:"display_name" yaml:"display_name"`
+	Company        string `json:"company" yaml:"company"`
+	Tag            string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Managed        bool   `json:"managed" yaml:"managed"`
+	DirectoryPath  string `json:"directory" yaml:"directory"`
+	ExecutablePath string `json:"executable" yaml:"executable"`
+	Registry       string `json:"registry,omitempty" yaml:"registry,omitempty"`
+	Scope          string `json:"scope" yaml:"scope"`
+	Arch           string `json:"arch" yaml:"arch"`
+	Distribution   string `json:"distribution" yaml:"distribution"`
+}
+
+func (i Installation) Document() InstallationDocument {
+	return InstallationDocument{
+		Version:        i.Version.String(),
+		Minor:          i.Version.getMinorString(),
+		FreeThreaded:   i.Version.FreeThreaded,
+		DisplayName:    i.DisplayName,
+		Company:        i.Company,
+		Tag:            i.Tag,
+		Managed:        i.Managed(),
+		DirectoryPath:  i.DirectoryPath,
+		ExecutablePath: i.ExecutablePath,
+		Registry:       i.Registry,
+		Scope:          i.Scope,
+		Arch:           i.Arch,
+		Distribution:   i.Distribution,
+	}
+}
+
+type StatusEntry struct {
+	InstallationDocument `yaml:",inline"`
+	// Updatable is the version which can be updated to. empty if already latest.
+	Updatable string `json:"updatable,omitempty" yaml:"updatable,omitempty"`
+}
+
+type StatusDocument struct {
+	Header        `yaml:",inline"`
+	Installations []StatusEntry `json:"installations" yaml:"installations"`
+}
+
+type ListDocument struct {
+	Header        `yaml:",inline"`
+	Installations []InstallationDocument `json:"installations" yaml:"installations"`
+}
+
+// status of UpdatePlanEntry.
+const (
+	UpdatePlanned = "planned"
+	UpdateDone    = "updated"
+	UpdateFailed  = "failed"
+)
+
+type UpdatePlanEntry struct {
+	Minor        string `json:"minor" yaml:"minor"`
+	FreeThreaded bool   `json:"free_threaded" yaml:"free_threaded"`
+	Arch         string `json:"arch" yaml:"arch"`
+	Scope        string `json:"scope" yaml:"scope"`
+	Installed    string `json:"installed" yaml:"installed"`
+	Target       string `json:"target" yaml:"target"`
+	Status       string `json:"status" yaml:"status"`
+	Error        string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+type UpdatePlanDocument struct {
+	Header  `yaml:",inline"`
+	Updates []UpdatePlanEntry `json:"updates" yaml:"updates"`
+}
+
+type InstallDocument struct {
+	Header          `yaml:",inline"`
+	Requested       string `json:"requested" yaml:"requested"`
+	Distribution    string `json:"distribution" yaml:"distribution"`
+	ForAllUser      bool   `json:"for_all_user" yaml:"for_all_user"`
+	TargetDirectory string `json:"target_directory,omitempty" yaml:"target_directory,omitempty"`
+	// Installed is the installed version. empty if canceled.
+	Installed string `json:"installed,omitempty" yaml:"installed,omitempty"`
+	Canceled  bool   `json:"canceled" yaml:"canceled"`
+}
+
+func NewInstallDocument(config Config, spec Specifier) InstallDocument {
+	d, err := distribution(config)
+	if err != nil {
+		d = config.Distribution
+	}
+	return InstallDocument{
+		Header:          newHeader("install"),
+		Requested:       spec.String(),
+		Distribution:    d,
+		ForAllUser:      config.ForAllUser,
+		TargetDirectory: config.TargetDirectory,
+		Canceled:        true,
+	}
+}
+
+type ErrorInfo struct {
+	Code     string `json:"code" yaml:"code"`
+	Message  string `json:"message" yaml:"message"`
+	ExitCode int    `json:"exit_code" yaml:"exit_code"`
+}
+
+type ErrorDocument struct {
+	Header `yaml:",inline"`
+	Error  ErrorInfo `json:"error" yaml:"error"`
+}
+
+// classifyError returns the error code and exit code of err.
+func classifyError(err error) (string, int) {
+	var usageErr *UsageError
+	var notFoundErr *NotFoundError
+	var statusErr *StatusError
+	var rateLimitErr *RateLimitError
+	var verificationErr *VerificationError
+	var offlineErr *OfflineError
+	switch {
+	case isCanceled(err):
+		return "canceled", ExitCanceled
+	case errors.As(err, &usageErr):
+		return "usage", ExitUsage
+	case errors.As(err, &notFoundErr), errors.Is(err, ErrPythonVersionFileNotFound), errors.Is(err, ErrNoPythonPinned):
+		return "not_found", ExitNotFound
+	case errors.As(err, &statusErr):
+		return "download_failed", ExitDownload
+	case errors.As(err, &rateLimitErr):
+		return "rate_limited", ExitRateLimit
+	case errors.As(err, &verificationErr):
+		return "verification_failed", ExitVerify
+	case errors.As(err, &offlineErr):
+		return "offline", ExitOffline
+	default:
+		return "error", ExitError
+	}
+}
+
+// ExitCode returns the exit code of pim for err.
+func ExitCode(err error) int {
+	_, code := classifyError(err)
+	return code
+}
+
+func NewErrorDocument(err error) ErrorDocument {
+	code, exitCode := classifyError(err)
+	return ErrorDocument{newHeader("error"), ErrorInfo{code, err.Error(), exitCode}}
+}
+
+// WriteDocument writes doc to stdout as OutputFormat. nothing is written for text format.
+func WriteDocument(doc any) error {
+	switch OutputFormat {
+	case OutputJSON:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	case OutputYAML:
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(doc); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return nil
+	}
+}
+
+// WriteSchema writes Schema to stdout.
+func WriteSchema() error {
+	_, err := io.WriteString(os.Stdout, Schema)
+	return err
+}
@@ -0,0 +1,221 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PEP 514: python installations are registered in HKLM/HKCU\Software\Python\<Company>\<Tag>.
+// 32bit python for all users is registered in 32bit view of HKLM (WOW6432Node) on 64bit windows.
+const (
+	pep514Path      = `Software\Python`
+	pep514Path32bit = `Software\WOW6432Node\Python`
+)
+
+type RegistryInfo struct {
+	Company         string
+	Tag             string
+	DisplayName     string
+	Version         string
+	SysVersion      string
+	SysArchitecture string
+	DirectoryPath   string
+	ExecutablePath  string
+	// Registry is "HKLM" or "HKCU".
+	Registry string
+}
+
+func readInfoFromRegistry(reader RegistryReader, root string, path string, company string, tag string) (RegistryInfo, bool) {
+	tagPath := path + `\` + company + `\` + tag
+
+	info := RegistryInfo{Company: company, Tag: tag, Registry: root}
+	var err error
+	// all values except InstallPath are optional. (PythonCore always has them)
+	info.DisplayName, err = reader.StringValue(root, tagPath, "DisplayName")
+	if err != nil {
+		info.DisplayName = "Python " + tag
+	}
+	// version of the distribution. same as python version only in PythonCore.
+	info.Version, _ = reader.StringValue(root, tagPath, "Version")
+	// "Major.Minor" of python.
+	info.SysVersion, _ = reader.StringValue(root, tagPath, "SysVersion")
+	// "32bit" or "64bit".
+	info.SysArchitecture, _ = reader.StringValue(root, tagPath, "SysArchitecture")
+	if info.SysArchitecture == "" && path == pep514Path32bit {
+		info.SysArchitecture = "32bit"
+	}
+
+	installPath := tagPath + `\InstallPath`
+	info.DirectoryPath, err = reader.StringValue(root, installPath, "")
+	if err != nil {
+		return RegistryInfo{}, false
+	}
+
+	info.ExecutablePath, err = reader.StringValue(root, installPath, "ExecutablePath")
+	if err != nil {
+		info.ExecutablePath = strings.TrimSuffix(info.DirectoryPath, `\`) + `\python.exe`
+	}
+
+	return info, true
+}
+
+func readRegistryInCompany(reader RegistryReader, root string, path string, company string) []RegistryInfo {
+	// skip PyLauncher. reserved and not company
+	if company == "PyLauncher" {
+		return nil
+	}
+
+	tagListNames, err := reader.SubKeyNames(root, path+`\`+company)
+	if err != nil {
+		return nil
+	}
+
+	var infos []RegistryInfo
+	for _, tag := range tagListNames {
+		if info, ok := readInfoFromRegistry(reader, root, path, company, tag); ok {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+func readRegistryFrom(reader RegistryReader, root string, path string) ([]RegistryInfo, error) {
+	companyListNames, err := reader.SubKeyNames(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []RegistryInfo
+	for _, company := range companyListNames {
+		infos = append(infos, readRegistryInCompany(reader, root, path, company)...)
+	}
+	return infos, nil
+}
+
+// readRegistry reads PEP 514 registry of all users (64bit and 32bit view) and current user.
+// the same tag can be registered in both HKLM and HKCU (installed for all users and for current user). both are kept.
+func readRegistry(reader RegistryReader) ([]RegistryInfo, error) {
+	var registryData []RegistryInfo
+	var lastErr error
+	foundPath := make(map[string]bool)
+	for _, key := range []struct {
+		root string
+		path string
+	}{
+		{"HKLM", pep514Path},
+		{"HKLM", pep514Path32bit},
+		{"HKCU", pep514Path},
+	} {
+		infos, err := readRegistryFrom(reader, key.root, key.path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				lastErr = err
+			}
+			continue
+		}
+		for _, info := range infos {
+			// the same installation may be seen in both views of HKLM. (e.g. key shared between views)
+			if foundPath[strings.ToLower(info.ExecutablePath)] {
+				continue
+			}
+			foundPath[strings.ToLower(info.ExecutablePath)] = true
+			registryData = append(registryData, info)
+		}
+	}
+	return registryData, lastErr
+}
+
+// parseTag returns free-threaded and arch of PythonCore tag, like "3.13t-arm64" or "3.12-32".
+func parseTag(tag string, sysArchitecture string) (bool, string) {
+	arch := "amd64"
+	if strings.HasSuffix(tag, "-arm64") {
+		tag, arch = strings.TrimSuffix(tag, "-arm64"), "arm64"
+	} else if strings.HasSuffix(tag, "-32") || sysArchitecture == "32bit" {
+		tag, arch = strings.TrimSuffix(tag, "-32"), "386"
+	}
+	return strings.HasSuffix(tag, "t"), arch
+}
+
+// registryVersion returns python version of the tag.
+// other than PythonCore, Version is of the distribution. so python version is read from the executable, or SysVersion.
+func registryVersion(company string, info RegistryInfo) (Version, error) {
+	if company == CompanyPythonCore {
+		return NewVersion(info.Version)
+	}
+	if v, err := readVersionFromExecutable(info.ExecutablePath); err == nil {
+		return v, nil
+	}
+	return NewVersion(info.SysVersion)
+}
+
+// registryInstallations returns installations registered in PEP 514 registry.
+// if company is not empty, only the company is returned. (version of some companies is read by running python)
+func registryInstallations(reader RegistryReader, company string) []Installation {
+	var installations []Installation
+
+	registryData, err := readRegistry(reader)
+	if err != nil && WithVerbose > 0 {
+		fmt.Fprintf(MessageOut(), "can not read registry: %s\n", err.Error())
+	}
+	for _, info := range registryData {
+		if company != "" && info.Company != company {
+			continue
+		}
+		v, err := registryVersion(info.Company, info)
+		if err != nil {
+			continue
+		}
+		arch := "amd64"
+		if info.Company == CompanyPythonCore {
+			// free-threaded build is registered as "3.13t".
+			v.FreeThreaded, arch = parseTag(info.Tag, info.SysArchitecture)
+		} else if info.SysArchitecture == "32bit" {
+			arch = "386"
+		}
+
+		scope := ScopeAllUser
+		if info.Registry == "HKCU" {
+			scope = ScopePerUser
+		}
+		installations = append(installations, Installation{
+			Version:        v,
+			DisplayName:    info.DisplayName,
+			Company:        info.Company,
+			DirectoryPath:  info.DirectoryPath,
+			ExecutablePath: info.ExecutablePath,
+			Tag:            info.Tag,
+			Registry:       info.Registry,
+			Scope:          scope,
+			Arch:           arch,
+			Distribution:   DistributionNative,
+		})
+	}
+
+	return installations
+}
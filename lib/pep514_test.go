@@ -0,0 +1,275 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// memoryRegistry is in-memory RegistryReader to test registry parsing off windows.
+// keys are like `HKLM\Software\Python\PythonCore\3.12`, and values are the values of the key.
+// parent keys are implicit. unlike windows, keys are case-sensitive.
+type memoryRegistry map[string]map[string]string
+
+func registryKeyName(root string, path string) string {
+	if path == "" {
+		return root
+	}
+	return root + `\` + path
+}
+
+func (m memoryRegistry) SubKeyNames(root string, path string) ([]string, error) {
+	key := registryKeyName(root, path)
+	_, found := m[key]
+	seen := make(map[string]bool)
+	var names []string
+	for k := range m {
+		rest, ok := strings.CutPrefix(k, key+`\`)
+		if !ok {
+			continue
+		}
+		found = true
+		name, _, _ := strings.Cut(rest, `\`)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("registry key %s: %w", key, os.ErrNotExist)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m memoryRegistry) StringValue(root string, path string, name string) (string, error) {
+	key := registryKeyName(root, path)
+	values, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("registry key %s: %w", key, os.ErrNotExist)
+	}
+	value, ok := values[name]
+	if !ok {
+		return "", fmt.Errorf("registry value %s in %s: %w", name, key, os.ErrNotExist)
+	}
+	return value, nil
+}
+
+// pythonCoreKeys returns keys of PythonCore tag registered by python installer.
+func pythonCoreKeys(root string, tag string, version string, dir string) memoryRegistry {
+	key := root + `\` + tag
+	return memoryRegistry{
+		key:                  {"DisplayName": "Python " + tag, "Version": version, "SysVersion": version[:4]},
+		key + `\InstallPath`: {"": dir, "ExecutablePath": dir + `python.exe`},
+	}
+}
+
+func mergeRegistry(registries ...memoryRegistry) memoryRegistry {
+	merged := make(memoryRegistry)
+	for _, registry := range registries {
+		for k, v := range registry {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+const (
+	testHKLMCore  = `HKLM\Software\Python\PythonCore`
+	testHKLM32    = `HKLM\Software\WOW6432Node\Python\PythonCore`
+	testHKCUCore  = `HKCU\Software\Python\PythonCore`
+	testHKCUPy    = `HKCU\Software\Python`
+	testHKLMPy    = `HKLM\Software\Python`
+	testDirPrefix = `C:\Python\`
+)
+
+// installationSummary is the fields of Installation checked by tests.
+func installationSummary(i Installation) string {
+	return fmt.Sprintf("%s/%s %s %s %s %s managed=%v", i.Company, i.Tag, i.Version.String(), i.Arch, i.Scope, i.ExecutablePath, i.Managed())
+}
+
+func TestRegistryInstallations(t *testing.T) {
+	tests := []struct {
+		name     string
+		registry memoryRegistry
+		want     []string
+	}{
+		{
+			name:     "empty",
+			registry: memoryRegistry{},
+			want:     nil,
+		},
+		{
+			name: "company only in HKCU",
+			registry: mergeRegistry(
+				pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+				memoryRegistry{
+					testHKCUPy + `\Astral\CPython3.11`:             {"DisplayName": "CPython 3.11", "SysVersion": "3.11", "SysArchitecture": "64bit"},
+					testHKCUPy + `\Astral\CPython3.11\InstallPath`: {"": `C:\uv\311\`},
+				},
+			),
+			want: []string{
+				`Astral/CPython3.11 3.11.0 amd64 per-user C:\uv\311\python.exe managed=false`,
+				`PythonCore/3.12 3.12.7 amd64 all-user C:\Python\312\python.exe managed=true`,
+			},
+		},
+		{
+			name: "same tag in HKLM and HKCU",
+			registry: mergeRegistry(
+				pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+				pythonCoreKeys(testHKCUCore, "3.12", "3.12.4", `C:\Users\u\Python312\`),
+			),
+			want: []string{
+				`PythonCore/3.12 3.12.4 amd64 per-user C:\Users\u\Python312\python.exe managed=true`,
+				`PythonCore/3.12 3.12.7 amd64 all-user C:\Python\312\python.exe managed=true`,
+			},
+		},
+		{
+			name: "32-bit in WOW6432Node",
+			registry: mergeRegistry(
+				pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+				pythonCoreKeys(testHKLM32, "3.12-32", "3.12.7", testDirPrefix+`312-32\`),
+			),
+			want: []string{
+				`PythonCore/3.12 3.12.7 amd64 all-user C:\Python\312\python.exe managed=true`,
+				`PythonCore/3.12-32 3.12.7 386 all-user C:\Python\312-32\python.exe managed=true`,
+			},
+		},
+		{
+			name: "32-bit by SysArchitecture in WOW6432Node",
+			registry: memoryRegistry{
+				testHKLM32 + `\3.8`:             {"Version": "3.8.10"},
+				testHKLM32 + `\3.8\InstallPath`: {"": testDirPrefix + `38\`},
+			},
+			want: []string{
+				`PythonCore/3.8 3.8.10 386 all-user C:\Python\38\python.exe managed=true`,
+			},
+		},
+		{
+			name: "tag suffixes",
+			registry: mergeRegistry(
+				pythonCoreKeys(testHKCUCore, "3.13", "3.13.1", `C:\u\313\`),
+				pythonCoreKeys(testHKCUCore, "3.13t", "3.13.1", `C:\u\313t\`),
+				pythonCoreKeys(testHKCUCore, "3.13-arm64", "3.13.1", `C:\u\313-arm64\`),
+				pythonCoreKeys(testHKCUCore, "3.13t-arm64", "3.13.1", `C:\u\313-arm64t\`),
+				pythonCoreKeys(testHKCUCore, "3.13-32", "3.13.1", `C:\u\313-32\`),
+				pythonCoreKeys(testHKCUCore, "3.13t-32", "3.13.1", `C:\u\313-32t\`),
+			),
+			want: []string{
+				`PythonCore/3.13 3.13.1 amd64 per-user C:\u\313\python.exe managed=true`,
+				`PythonCore/3.13-32 3.13.1 386 per-user C:\u\313-32\python.exe managed=true`,
+				`PythonCore/3.13-arm64 3.13.1 arm64 per-user C:\u\313-arm64\python.exe managed=true`,
+				`PythonCore/3.13t 3.13.1t amd64 per-user C:\u\313t\python.exe managed=true`,
+				`PythonCore/3.13t-32 3.13.1t 386 per-user C:\u\313-32t\python.exe managed=true`,
+				`PythonCore/3.13t-arm64 3.13.1t arm64 per-user C:\u\313-arm64t\python.exe managed=true`,
+			},
+		},
+		{
+			name: "missing InstallPath",
+			registry: mergeRegistry(
+				pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+				memoryRegistry{testHKLMCore + `\3.11`: {"Version": "3.11.9"}},
+			),
+			want: []string{
+				`PythonCore/3.12 3.12.7 amd64 all-user C:\Python\312\python.exe managed=true`,
+			},
+		},
+		{
+			name: "default ExecutablePath",
+			registry: memoryRegistry{
+				testHKLMCore + `\3.10`:             {"Version": "3.10.11"},
+				testHKLMCore + `\3.10\InstallPath`: {"": `C:\Python310`},
+			},
+			want: []string{
+				`PythonCore/3.10 3.10.11 amd64 all-user C:\Python310\python.exe managed=true`,
+			},
+		},
+		{
+			name:     "microsoft store",
+			registry: pythonCoreKeys(testHKCUCore, "3.12", "3.12.7", `C:\Program Files\WindowsApps\PythonSoftwareFoundation.Python.3.12_3.12.2032.0_x64__qbz5n2kfra8p0\`),
+			want: []string{
+				`PythonCore/3.12 3.12.7 amd64 per-user C:\Program Files\WindowsApps\PythonSoftwareFoundation.Python.3.12_3.12.2032.0_x64__qbz5n2kfra8p0\python.exe managed=false`,
+			},
+		},
+		{
+			name: "unmanaged company",
+			registry: memoryRegistry{
+				testHKLMPy + `\ContinuumAnalytics\Anaconda39-64`:             {"DisplayName": "Anaconda 2022.10", "Version": "2022.10", "SysVersion": "3.9", "SysArchitecture": "64bit"},
+				testHKLMPy + `\ContinuumAnalytics\Anaconda39-64\InstallPath`: {"": `C:\Anaconda3`, "ExecutablePath": `C:\Anaconda3\python.exe`},
+				testHKLMPy + `\PyLauncher`:                                   {"": "reserved"},
+				testHKLMPy + `\PyLauncher\InstallPath`:                       {"": `C:\Windows\`},
+			},
+			want: []string{
+				`ContinuumAnalytics/Anaconda39-64 3.9.0 amd64 all-user C:\Anaconda3\python.exe managed=false`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, installation := range registryInstallations(tt.registry, "") {
+				got = append(got, installationSummary(installation))
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("registryInstallations =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestInstallKeySideBySide(t *testing.T) {
+	registry := mergeRegistry(
+		pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+		pythonCoreKeys(testHKLM32, "3.12-32", "3.12.7", testDirPrefix+`312-32\`),
+		pythonCoreKeys(testHKCUCore, "3.12", "3.12.4", `C:\Users\u\Python312\`),
+	)
+	keys := make(map[installKey]bool)
+	for _, installation := range registryInstallations(registry, "") {
+		keys[installation.key()] = true
+	}
+	if len(keys) != 3 {
+		t.Errorf("side by side installations have %d keys, want 3: %v", len(keys), keys)
+	}
+}
+
+func TestRegistryInstallationsOfCompany(t *testing.T) {
+	registry := mergeRegistry(
+		pythonCoreKeys(testHKLMCore, "3.12", "3.12.7", testDirPrefix+`312\`),
+		memoryRegistry{
+			testHKLMPy + `\ContinuumAnalytics\Anaconda39-64`:             {"SysVersion": "3.9"},
+			testHKLMPy + `\ContinuumAnalytics\Anaconda39-64\InstallPath`: {"": `C:\Anaconda3`},
+		},
+	)
+	installations := registryInstallations(registry, CompanyPythonCore)
+	if len(installations) != 1 || installations[0].Company != CompanyPythonCore {
+		t.Errorf("registryInstallations(%s) = %v", CompanyPythonCore, installations)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	progressInterval = 100 * time.Millisecond
+)
+
+// progress shows a progress bar of download to stderr. it is shown only if stderr is a terminal.
+type progress struct {
+	name     string
+	current  int64
+	total    int64 // -1 if unknown.
+	start    int64 // resumed bytes. excluded from rate.
+	started  time.Time
+	drawn    time.Time
+	disabled bool
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func newProgress(name string, start int64, total int64) *progress {
+	return &progress{
+		name:     name,
+		current:  start,
+		total:    total,
+		start:    start,
+		started:  time.Now(),
+		disabled: !isTerminal(os.Stderr),
+	}
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.current += int64(len(b))
+	if time.Since(p.drawn) >= progressInterval {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+func (p *progress) draw() {
+	if p.disabled {
+		return
+	}
+	p.drawn = time.Now()
+
+	elapsed := time.Since(p.started).Seconds()
+	rate := 0.0
+	if elapsed > 0 {
+		rate = float64(p.current-p.start) / elapsed
+	}
+
+	line := fmt.Sprintf("%s %s", p.name, formatBytes(p.current))
+	if p.total > 0 {
+		const width = 20
+		filled := int(float64(width) * float64(p.current) / float64(p.total))
+		if filled > width {
+			filled = width
+		}
+		line = fmt.Sprintf(
+			"%s [%s%s] %s / %s",
+			p.name, strings.Repeat("=", filled), strings.Repeat(" ", width-filled), formatBytes(p.current), formatBytes(p.total),
+		)
+	}
+	line += fmt.Sprintf(" %s/s", formatBytes(int64(rate)))
+	if p.total > 0 && rate > 0 {
+		eta := time.Duration(float64(p.total-p.current)/rate) * time.Second
+		line += fmt.Sprintf(" ETA %s", eta.Round(time.Second))
+	}
+	// "\033[K" clears rest of the line.
+	fmt.Fprintf(os.Stderr, "\r%s\033[K", line)
+}
+
+// Done draws the final state and ends the line.
+func (p *progress) Done() {
+	if p.disabled {
+		return
+	}
+	p.draw()
+	fmt.Fprintln(os.Stderr)
+}
@@ -0,0 +1,122 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultProvider = "cpython"
+)
+
+// ArtifactKind is how an artifact is installed.
+type ArtifactKind int
+
+const (
+	// ArtifactInstaller is an official windows installer (.exe).
+	ArtifactInstaller ArtifactKind = iota
+	// ArtifactSource is a source tarball built with configure/make.
+	ArtifactSource
+	// ArtifactArchive is a relocatable prebuilt archive, extracted into versioned directory.
+	ArtifactArchive
+)
+
+// Verification is where the expected digest of an artifact is published. (see verifyArtifact)
+type Verification int
+
+const (
+	// VerifyNone means no digest is published. the artifact is used only with AllowUnverifiedDownloads.
+	VerifyNone Verification = iota
+	// VerifySha256 uses Artifact.Sha256 given by the provider.
+	VerifySha256
+	// VerifyPythonOrg uses SHA-256 and sigstore bundle published by python.org API.
+	VerifyPythonOrg
+	// VerifyStandaloneSums uses SHA256SUMS (or "<file>.sha256") of python-build-standalone release.
+	VerifyStandaloneSums
+)
+
+// Artifact is a downloadable file which installs a python version.
+type Artifact struct {
+	Kind     ArtifactKind
+	Url      string
+	FileName string
+	// Root is the directory of python in the archive. (ArtifactArchive only)
+	Root         string
+	Verification Verification
+	// Sha256 is the expected digest. (VerifySha256 only)
+	Sha256 string
+}
+
+// Provider provides python versions and artifacts to install them.
+type Provider interface {
+	// Name returns provider name. it is used as config value and cache key.
+	Name() string
+	// ListVersions returns all versions provided.
+	ListVersions(ctx context.Context) ([]Version, error)
+	// ResolveArtifact returns the artifact of version for arch. (GOARCH style, e.g. "amd64", "arm64")
+	ResolveArtifact(ctx context.Context, version Version, arch string) (Artifact, error)
+}
+
+// ProviderFactory creates Provider from config.
+type ProviderFactory func(config Config) Provider
+
+var (
+	providerFactories = make(map[string]ProviderFactory)
+)
+
+// RegisterProvider registers factory as name. it is expected to be called in init.
+func RegisterProvider(name string, factory ProviderFactory) {
+	if _, ok := providerFactories[name]; ok {
+		panic(fmt.Sprintf("provider is already registered: %s", name))
+	}
+	providerFactories[name] = factory
+}
+
+// ProviderNames returns registered provider names.
+func ProviderNames() []string {
+	names := make([]string, 0, len(providerFactories))
+	for name := range providerFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProvider returns Provider chosen by config.
+func NewProvider(config Config) (Provider, error) {
+	name := config.Provider
+	if name == "" {
+		name = DefaultProvider
+	}
+	factory, ok := providerFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown provider: %s (available: %s)", name, strings.Join(ProviderNames(), ", "))
+	}
+	return factory(config), nil
+}
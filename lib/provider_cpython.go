@@ -0,0 +1,171 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	BaseUrl = "https://api.github.com/repos/python/cpython/tags?per_page=100&page=%d"
+)
+
+func init() {
+	RegisterProvider(DefaultProvider, func(config Config) Provider {
+		return &cpythonProvider{config: config}
+	})
+}
+
+// cpythonProvider provides python/cpython. versions are fetched from GitHub tags.
+type cpythonProvider struct {
+	config            Config
+	standaloneRelease string
+}
+
+type Tag struct {
+	Name   string `json:"name"`
+	Commit struct {
+		Sha string `json:"sha"`
+		Url string `json:"url"`
+	} `json:"commit"`
+	ZipballUrl string `json:"zipball_url"`
+	TarballUrl string `json:"tarball_url"`
+	NodeId     string `json:"node_id"`
+}
+
+func getVersionsByPage(ctx context.Context, config Config, page int) ([]Version, error) {
+	url := fmt.Sprintf(BaseUrl, page)
+
+	req, err := newGitHubRequest(ctx, config, url)
+	if err != nil {
+		return nil, err
+	}
+	cached, hasCache := githubPages[page]
+	if hasCache && cached.ETag != "" {
+		req.Header.Set("if-none-match", cached.ETag)
+	}
+
+	resp, err := httpDo(config, req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer deferErrCheck(resp.Body.Close)
+	if err := checkGitHubResponse(resp, http.StatusOK, http.StatusNotModified); err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified {
+		return cached.Versions, nil
+	}
+	byteArray, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []Tag
+	err = json.Unmarshal(byteArray, &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, tag := range tags {
+		// tag Name prefix is not v3, skip
+		// has prefix
+		if !strings.HasPrefix(tag.Name, "v3") {
+			continue
+		}
+		v, err := NewVersion(tag.Name)
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	githubPages[page] = GitHubPageCache{ETag: resp.Header.Get("etag"), Versions: versions}
+	return versions, nil
+}
+
+func (p *cpythonProvider) Name() string {
+	return DefaultProvider
+}
+
+func (p *cpythonProvider) ListVersions(ctx context.Context) ([]Version, error) {
+	i := 1
+	fetchedVersions_ := make([]Version, 0)
+	isFetched := make(map[int]bool)
+	maxMinor := -1
+
+	for {
+		versions, err := getVersionsByPage(ctx, p.config, i)
+		if err != nil {
+			return nil, err
+		}
+
+		fetchedVersions_ = append(fetchedVersions_, versions...)
+
+		// check if all minor versions from supportedMinimumMinorVersion to latestMinorVersion are fetched.
+		// GitHub API return tags sorted by name.
+		for _, v := range versions {
+			isFetched[v.Minor] = true
+			maxMinor = max(maxMinor, v.Minor)
+		}
+		flag := false
+		for i := supportedMinimumMinorVersion - 1; i <= maxMinor; i++ {
+			if _, ok := isFetched[i]; !ok {
+				flag = true
+			}
+		}
+		if !flag { // ok
+			break
+		}
+
+		// read next page
+		i++
+	}
+
+	return fetchedVersions_, nil
+}
+
+func (p *cpythonProvider) ResolveArtifact(ctx context.Context, version Version, arch string) (Artifact, error) {
+	d, err := distribution(p.config)
+	if err != nil {
+		return Artifact{}, err
+	}
+	if d == DistributionStandalone {
+		if p.standaloneRelease == "" {
+			if p.standaloneRelease, err = standaloneRelease(ctx, p.config); err != nil {
+				return Artifact{}, err
+			}
+		}
+		return standaloneArtifact(p.config, version, arch, p.standaloneRelease)
+	}
+	return nativeArtifact(p.config, version, arch)
+}
@@ -0,0 +1,188 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// python.org FTP directory index (https://www.python.org/ftp/python/)
+// - index: <a href="3.12.7/">3.12.7/</a>
+// - sub-listing: <a href="python-3.12.7-amd64.exe">python-3.12.7-amd64.exe</a>
+const (
+	DefaultPythonFtpBaseUrl = "https://www.python.org/ftp/python"
+	PythonOrgProvider       = "python.org"
+	pythonOrgFetchWorkers   = 8
+)
+
+var (
+	hrefRegex       = regexp.MustCompile(`<a href="([^"?/]+/?)"`)
+	versionDirRegex = regexp.MustCompile(`^\d+\.\d+\.\d+/$`)
+)
+
+func init() {
+	RegisterProvider(PythonOrgProvider, func(config Config) Provider {
+		return &pythonOrgProvider{cpythonProvider{config: config}}
+	})
+}
+
+func pythonFtpBaseUrl(config Config) string {
+	if config.PythonFtpBaseUrl != "" {
+		return strings.TrimSuffix(config.PythonFtpBaseUrl, "/")
+	}
+	return DefaultPythonFtpBaseUrl
+}
+
+// pythonOrgProvider provides python/cpython. versions are read from python.org FTP directory index.
+// only versions which have artifacts for current arch are listed, so failedMinimumVersions is rarely needed.
+// artifacts are same as cpythonProvider.
+type pythonOrgProvider struct {
+	cpythonProvider
+}
+
+func (p *pythonOrgProvider) Name() string {
+	return PythonOrgProvider
+}
+
+// readDirectoryIndex returns linked names in the directory index at url.
+func readDirectoryIndex(ctx context.Context, config Config, url string) ([]string, error) {
+	resp, err := httpGet(ctx, config, url)
+	if err != nil {
+		return nil, err
+	}
+	defer deferErrCheck(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{resp.StatusCode}
+	}
+	byteArray, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, match := range hrefRegex.FindAllStringSubmatch(string(byteArray), -1) {
+		names = append(names, match[1])
+	}
+	return names, nil
+}
+
+// versionsInDirectory returns versions which have artifacts in the version directory.
+// the directory of final release has also its pre-releases. (e.g. 3.13.0/ has python-3.13.0rc1-amd64.exe)
+func versionsInDirectory(ctx context.Context, config Config, baseUrl string, dir string, artifactRegex *regexp.Regexp) ([]Version, error) {
+	names, err := readDirectoryIndex(ctx, config, fmt.Sprintf("%s/%s", baseUrl, dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, name := range names {
+		match := artifactRegex.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+		v, err := NewVersion(match[1])
+		if err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, nil
+}
+
+func (p *pythonOrgProvider) ListVersions(ctx context.Context) ([]Version, error) {
+	baseUrl := pythonFtpBaseUrl(p.config)
+	names, err := readDirectoryIndex(ctx, p.config, baseUrl+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []string
+	for _, name := range names {
+		if !versionDirRegex.MatchString(name) {
+			continue
+		}
+		// same as GitHub tags, older versions are not needed.
+		v, err := NewVersion(strings.TrimSuffix(name, "/"))
+		if err != nil || v.Major != 3 || v.Minor < supportedMinimumMinorVersion {
+			continue
+		}
+		dirs = append(dirs, name)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		versions []Version
+	)
+	queue := make(chan string)
+	for i := 0; i < pythonOrgFetchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for dir := range queue {
+				vs, err := versionsInDirectory(ctx, p.config, baseUrl, dir, nativeArtifactRegex)
+				// a broken or removed directory does not make other versions unusable.
+				if err != nil {
+					if WithVerbose > 0 && !isCanceled(err) {
+						fmt.Fprintf(MessageOut(), "skip %s: %s\n", dir, err.Error())
+					}
+					continue
+				} else if len(vs) == 0 {
+					if WithVerbose > 0 {
+						fmt.Fprintf(MessageOut(), "skip %s: no installer\n", dir)
+					}
+					continue
+				}
+				mu.Lock()
+				versions = append(versions, vs...)
+				mu.Unlock()
+			}
+		}()
+	}
+	go func() {
+		// workers stop after the fetching directory, if ctx is done.
+		defer close(queue)
+		for _, dir := range dirs {
+			select {
+			case queue <- dir:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
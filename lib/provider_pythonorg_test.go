@@ -0,0 +1,149 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ftpIndex returns a directory index like python.org FTP.
+func ftpIndex(names ...string) []byte {
+	var b strings.Builder
+	b.WriteString("<html><body><pre><a href=\"../\">../</a>\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>   01-Oct-2024 00:00   -\n", name, name)
+	}
+	b.WriteString("</pre></body></html>\n")
+	return []byte(b.String())
+}
+
+// installerName returns the file name of installer of version for current arch.
+func installerName(t *testing.T, version string) string {
+	t.Helper()
+	v, err := NewVersion(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := nativeArtifact(Config{}, v, runtime.GOARCH)
+	if err != nil {
+		t.Skip(err)
+	}
+	return artifact.FileName
+}
+
+func TestPythonOrgListVersions(t *testing.T) {
+	files := map[string][]byte{
+		"/ftp/python/": ftpIndex("2.7.18/", "3.8.20/", "3.11.9/", "3.12.7/", "3.13.0/", "3.14.0/", "doc/", "index.html"),
+		// older versions are not fetched.
+		"/ftp/python/2.7.18/": ftpIndex(installerName(t, "2.7.18")),
+		"/ftp/python/3.8.20/": ftpIndex(installerName(t, "3.8.20")),
+		// 3.11.9/ has no installer.
+		"/ftp/python/3.11.9/": ftpIndex("Python-3.11.9.tar.xz.asc"),
+		"/ftp/python/3.12.7/": ftpIndex(installerName(t, "3.12.7"), installerName(t, "3.12.7")+".asc"),
+		"/ftp/python/3.13.0/": ftpIndex(installerName(t, "3.13.0rc1"), installerName(t, "3.13.0")),
+		// 3.14.0/ is 404.
+	}
+
+	tests := []struct {
+		name    string
+		files   map[string][]byte
+		cancel  bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "skip broken directories", files: files, want: []string{"3.12.7", "3.13.0", "3.13.0rc1"}},
+		{name: "root is 404", files: map[string][]byte{}, wantErr: true},
+		{name: "canceled", files: files, cancel: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fileServer{files: tt.files}
+			ts := httptest.NewServer(server)
+			defer ts.Close()
+			useHttpClient(t, &httpClient{ts.Client(), 5 * time.Second, 0})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			provider := &pythonOrgProvider{cpythonProvider{config: Config{PythonFtpBaseUrl: ts.URL + "/ftp/python/"}}}
+			versions, err := provider.ListVersions(ctx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ListVersions = %v, want error", versions)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListVersions: %v", err)
+			}
+
+			got := make([]string, len(versions))
+			for i, v := range versions {
+				got[i] = v.getFullString()
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListVersions = %v, want %v", got, tt.want)
+			}
+			for _, path := range server.requests {
+				if strings.Contains(path, "2.7.18") || strings.Contains(path, "3.8.20") {
+					t.Errorf("unsupported version is fetched: %s", path)
+				}
+			}
+		})
+	}
+}
+
+func TestPythonOrgArtifactUrl(t *testing.T) {
+	version, _ := NewVersion("3.13.0rc1")
+	tests := []struct {
+		name    string
+		baseUrl string
+		want    string
+	}{
+		{name: "default", baseUrl: "", want: DefaultPythonFtpBaseUrl + "/3.13.0/"},
+		{name: "mirror", baseUrl: "http://mirror.example/python/", want: "http://mirror.example/python/3.13.0/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			artifact, err := nativeArtifact(Config{PythonFtpBaseUrl: tt.baseUrl}, version, runtime.GOARCH)
+			if err != nil {
+				t.Skip(err)
+			}
+			if want := tt.want + artifact.FileName; artifact.Url != want {
+				t.Errorf("Url = %s, want %s", artifact.Url, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testProviderName = "test"
+
+// testProvider provides versions and archives given by the test.
+type testProvider struct {
+	versions []Version
+	baseUrl  string
+	sha256   map[string]string // version -> sha256
+}
+
+var currentTestProvider *testProvider
+
+func init() {
+	RegisterProvider(testProviderName, func(config Config) Provider { return currentTestProvider })
+}
+
+func (p *testProvider) Name() string {
+	return testProviderName
+}
+
+func (p *testProvider) ListVersions(ctx context.Context) ([]Version, error) {
+	return p.versions, nil
+}
+
+func (p *testProvider) ResolveArtifact(ctx context.Context, version Version, arch string) (Artifact, error) {
+	fileName := fmt.Sprintf("python-%s-%s.tar.gz", version.String(), arch)
+	return Artifact{
+		Kind:         ArtifactArchive,
+		Url:          p.baseUrl + "/" + fileName,
+		FileName:     fileName,
+		Root:         "python/",
+		Verification: VerifySha256,
+		Sha256:       p.sha256[version.String()],
+	}, nil
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		provider string
+		want     string
+		wantErr  bool
+	}{
+		{"", DefaultProvider, false},
+		{DefaultProvider, DefaultProvider, false},
+		{PythonOrgProvider, PythonOrgProvider, false},
+		{testProviderName, testProviderName, false},
+		{"pypy", "", true},
+	}
+	currentTestProvider = &testProvider{}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			provider, err := NewProvider(Config{Provider: tt.provider})
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), DefaultProvider) {
+					t.Fatalf("NewProvider error = %v, want error with available providers", err)
+				}
+				return
+			}
+			if err != nil || provider.Name() != tt.want {
+				t.Fatalf("NewProvider = %v, %v, want %s", provider, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterProviderTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterProvider does not panic for registered name")
+		}
+	}()
+	RegisterProvider(DefaultProvider, func(config Config) Provider { return nil })
+}
+
+func TestInstallPythonWithProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		served    []string
+		wrongHash bool
+		want      string
+		// wantErr is the error code. (see classifyError)
+		wantErr string
+	}{
+		{name: "newest of the minor", spec: "3.12", served: []string{"3.12.6", "3.12.7"}, want: "3.12.7"},
+		{name: "exact", spec: "3.12.6", served: []string{"3.12.6", "3.12.7"}, want: "3.12.6"},
+		{name: "fall back to older", spec: "3.12", served: []string{"3.12.6"}, want: "3.12.6"},
+		{name: "not provided", spec: "3.11", served: []string{"3.12.7"}, wantErr: "not_found"},
+		{name: "hash mismatch", spec: "3.12", served: []string{"3.12.7"}, wrongHash: true, wantErr: "verification_failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempDirs(t)
+			resetVersions(t)
+			server := &fileServer{files: make(map[string][]byte)}
+			provider := &testProvider{baseUrl: useTestServer(t, server).URL, sha256: make(map[string]string)}
+			for _, v := range []string{"3.12.6", "3.12.7", "3.13.0"} {
+				version, _ := NewVersion(v)
+				provider.versions = append(provider.versions, version)
+			}
+			for _, v := range tt.served {
+				version, _ := NewVersion(v)
+				artifact, _ := provider.ResolveArtifact(context.Background(), version, runtime.GOARCH)
+				archive := writeTestArchive(t, tarFile("python/README", v))
+				server.files["/"+artifact.FileName] = archive
+				provider.sha256[v] = sha256Hex(archive)
+				if tt.wrongHash {
+					provider.sha256[v] = strings.Repeat("0", 64)
+				}
+			}
+			currentTestProvider = provider
+			config := Config{Provider: testProviderName, Distribution: DistributionStandalone}
+			p, err := NewProvider(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spec, err := ParsePinnedVersion(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			installed, err := InstallPython(context.Background(), config, p, spec)
+			if tt.wantErr != "" {
+				if code, _ := classifyError(err); err == nil || code != tt.wantErr {
+					t.Fatalf("InstallPython error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || installed.String() != tt.want {
+				t.Fatalf("InstallPython = %s, %v, want %s", installed.String(), err, tt.want)
+			}
+			cache, err := loadCacheFile()
+			if err != nil || cache.Provider != testProviderName || len(cache.AllVersions) != len(provider.versions) {
+				t.Errorf("version cache = %+v, %v, want versions of %s", cache, err, testProviderName)
+			}
+		})
+	}
+}
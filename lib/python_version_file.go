@@ -0,0 +1,131 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// .python-version is the file used by pyenv to pin python version of the project.
+// the first version in the file is used. comments ("#") and empty lines are ignored.
+// pyenv style version ("3.12", "3.12.1", "3.13t") and PEP 440 version specifier (">=3.11,<3.13") are supported.
+const (
+	PythonVersionFileName = ".python-version"
+)
+
+var (
+	ErrPythonVersionFileNotFound = errors.New(PythonVersionFileName + " is not found")
+)
+
+// FindPythonVersionFile walks up from dir and returns the path of the nearest .python-version.
+func FindPythonVersionFile(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, PythonVersionFileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrPythonVersionFileNotFound
+		}
+		dir = parent
+	}
+}
+
+// ReadPythonVersionFile returns the first version in the file.
+func ReadPythonVersionFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer deferErrCheck(f.Close)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		// pyenv allows multiple versions separated by whitespace.
+		if fields := strings.Fields(line); len(fields) > 0 {
+			return fields[0], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no version in %s", path)
+}
+
+// WritePythonVersionFile writes version into .python-version in dir, and returns the path.
+func WritePythonVersionFile(dir string, version string) (string, error) {
+	if _, err := ParsePinnedVersion(version); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, PythonVersionFileName)
+	return path, os.WriteFile(path, []byte(version+"\n"), 0644)
+}
+
+// ParsePinnedVersion parses the version in .python-version.
+// as same as pyenv, "Major.Minor" means the latest version of the minor version.
+func ParsePinnedVersion(version string) (Specifier, error) {
+	if v, err := NewVersion(version); err == nil {
+		switch releaseSegments(version) {
+		case 1:
+			return ParseSpecifier(fmt.Sprintf("==%d.*", v.Major))
+		case 2:
+			return NewMinorSpecifier(v), nil
+		}
+		return NewExactSpecifier(v), nil
+	}
+	spec, err := ParseSpecifier(version)
+	if err != nil {
+		return Specifier{}, fmt.Errorf("unsupported version in %s: %s", PythonVersionFileName, version)
+	}
+	return spec, nil
+}
+
+// FindPinnedVersion returns the version in the nearest .python-version from dir, and the path of the file.
+func FindPinnedVersion(dir string) (Specifier, string, error) {
+	path, err := FindPythonVersionFile(dir)
+	if err != nil {
+		return Specifier{}, "", err
+	}
+	version, err := ReadPythonVersionFile(path)
+	if err != nil {
+		return Specifier{}, path, err
+	}
+	spec, err := ParsePinnedVersion(version)
+	return spec, path, err
+}
@@ -0,0 +1,70 @@
+//go:build windows
+
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// windowsRegistry is RegistryReader of this machine.
+type windowsRegistry struct{}
+
+func openRegistryKey(root string, path string) (registry.Key, error) {
+	var from registry.Key
+	switch root {
+	case "HKLM":
+		from = registry.LOCAL_MACHINE
+	case "HKCU":
+		from = registry.CURRENT_USER
+	default:
+		return 0, fmt.Errorf("unknown registry root: %s", root)
+	}
+	return registry.OpenKey(from, path, registry.READ)
+}
+
+func (windowsRegistry) SubKeyNames(root string, path string) ([]string, error) {
+	key, err := openRegistryKey(root, path)
+	if err != nil {
+		return nil, err
+	}
+	defer deferErrCheck(key.Close)
+
+	return key.ReadSubKeyNames(-1)
+}
+
+func (windowsRegistry) StringValue(root string, path string, name string) (string, error) {
+	key, err := openRegistryKey(root, path)
+	if err != nil {
+		return "", err
+	}
+	defer deferErrCheck(key.Close)
+
+	value, _, err := key.GetStringValue(name)
+	return value, err
+}
@@ -0,0 +1,191 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// remoteListLimit is the number of versions shown for each minor version, unless all versions are requested.
+	remoteListLimit = 5
+)
+
+// RemoteListOptions selects versions shown by ListRemoteCommand.
+//   - Minor: only the minor version (e.g. "3.12") is shown, with all versions of it.
+//   - PreRelease: pre-releases are shown.
+//   - All: all versions of all minor versions (including unsupported old ones) are shown.
+type RemoteListOptions struct {
+	Minor      string
+	PreRelease bool
+	All        bool
+}
+
+type RemoteVersion struct {
+	Version               string `json:"version" yaml:"version"`
+	PreRelease            bool   `json:"pre_release" yaml:"pre_release"`
+	NoInstaller           bool   `json:"no_installer" yaml:"no_installer"`
+	Installed             bool   `json:"installed" yaml:"installed"`
+	InstalledFreeThreaded bool   `json:"installed_free_threaded" yaml:"installed_free_threaded"`
+}
+
+func (v RemoteVersion) marks() []string {
+	var marks []string
+	if v.PreRelease {
+		marks = append(marks, "pre-release")
+	}
+	if v.NoInstaller {
+		marks = append(marks, "no installer")
+	}
+	if v.Installed {
+		marks = append(marks, "installed")
+	}
+	if v.InstalledFreeThreaded {
+		marks = append(marks, "installed free-threaded")
+	}
+	return marks
+}
+
+type RemoteMinor struct {
+	Minor    string          `json:"minor" yaml:"minor"`
+	Versions []RemoteVersion `json:"versions" yaml:"versions"`
+}
+
+type RemoteListDocument struct {
+	Header   `yaml:",inline"`
+	Provider string        `json:"provider" yaml:"provider"`
+	Minors   []RemoteMinor `json:"minors" yaml:"minors"`
+}
+
+// ListRemoteCommand shows versions known by provider, grouped by minor version.
+// versions are read from cache if it is fresh, so this works offline.
+func ListRemoteCommand(ctx context.Context, config Config, provider Provider, options RemoteListOptions) error {
+	var minor *Version
+	if options.Minor != "" {
+		v, err := NewVersion(options.Minor)
+		if err != nil {
+			return &UsageError{err}
+		}
+		if v.Count() != 2 {
+			return &UsageError{fmt.Errorf("minor version must be only 'Major.Minor': %s", options.Minor)}
+		}
+		minor = &v
+	}
+
+	if err := fetchLatestVersions(ctx, config, provider); err != nil {
+		return err
+	}
+
+	installed := make(map[string]bool)
+	installedFreeThreaded := make(map[string]bool)
+	for _, v := range getPythonVersions(config) {
+		if v.FreeThreaded {
+			v.FreeThreaded = false
+			installedFreeThreaded[v.String()] = true
+		} else {
+			installed[v.String()] = true
+		}
+	}
+
+	source := allVersions
+	if !options.All {
+		// only supported minor versions.
+		source = nil
+		for _, versionList := range fetchedVersions {
+			for v := versionList.Front(); v != nil; v = v.Next() {
+				source = append(source, v.Value)
+			}
+		}
+	}
+
+	showPreRelease := options.PreRelease || config.AllowPreRelease
+	groups := make(map[[2]int][]Version)
+	for _, v := range source {
+		if minor != nil && (v.Major != minor.Major || v.Minor != minor.Minor) {
+			continue
+		}
+		if v.IsPreRelease() && !showPreRelease {
+			continue
+		}
+		key := [2]int{v.Major, v.Minor}
+		groups[key] = append(groups[key], v)
+	}
+
+	keys := make([][2]int, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] > keys[j][0]
+		}
+		return keys[i][1] > keys[j][1]
+	})
+
+	doc := RemoteListDocument{Header: newHeader("remote-list"), Provider: provider.Name(), Minors: []RemoteMinor{}}
+	for _, key := range keys {
+		versions := groups[key]
+		sort.Slice(versions, func(i, j int) bool { return versions[i].GreaterThan(versions[j]) })
+		if !options.All && minor == nil && len(versions) > remoteListLimit {
+			versions = versions[:remoteListLimit]
+		}
+
+		group := RemoteMinor{Minor: fmt.Sprintf("%d.%d", key[0], key[1]), Versions: []RemoteVersion{}}
+		for _, v := range versions {
+			failed, ok := failedMinimumVersions[v.Minor]
+			group.Versions = append(group.Versions, RemoteVersion{
+				Version:               v.String(),
+				PreRelease:            v.IsPreRelease(),
+				NoInstaller:           ok && failed.LessThanOrEqual(v),
+				Installed:             installed[v.String()],
+				InstalledFreeThreaded: installedFreeThreaded[v.String()],
+			})
+		}
+		doc.Minors = append(doc.Minors, group)
+	}
+
+	if IsStructuredOutput() {
+		return WriteDocument(doc)
+	}
+
+	if len(doc.Minors) == 0 {
+		fmt.Fprintln(MessageOut(), "There is no version.")
+		return nil
+	}
+	for _, group := range doc.Minors {
+		fmt.Fprintf(MessageOut(), "%s:\n", group.Minor)
+		for _, v := range group.Versions {
+			if marks := v.marks(); len(marks) > 0 {
+				fmt.Fprintf(MessageOut(), "  %s (%s)\n", v.Version, strings.Join(marks, ", "))
+			} else {
+				fmt.Fprintf(MessageOut(), "  %s\n", v.Version)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// shims are small scripts which call "pim shim exec <name>".
+// python version is resolved in this order:
+//  1. PIM_PYTHON environment variable
+//  2. the nearest .python-version from current directory
+//  3. DefaultPython in config
+//
+// "pythonX.Y" shim always uses the minor version.
+const (
+	PythonEnvName = "PIM_PYTHON"
+	shimMarker    = "generated by pim shim. do not edit."
+	// set while pim runs other python (e.g. to read the version), to detect shims calling themselves.
+	shimDisabledEnvName = "PIM_SHIM_DISABLED"
+)
+
+var (
+	shimDir           string
+	shimNames         = []string{"python", "python3", "pip", "pip3"}
+	minorShimRegex    = regexp.MustCompile(`^python(\d+\.\d+t?)$`)
+	ErrNoPythonPinned = errors.New("python version is not pinned. set " + PythonEnvName + ", .python-version (pim local) or DefaultPython in config")
+)
+
+func init() {
+	shimDir = filepath.Join(dataDir, "bin")
+}
+
+func ShimDir() string {
+	return shimDir
+}
+
+func isShimDir(dir string) bool {
+	if dir == "" {
+		return false
+	}
+	abs, err := filepath.Abs(dir)
+	return err == nil && filepath.Clean(abs) == filepath.Clean(shimDir)
+}
+
+// environWithoutShims returns environment variables for commands run by pim.
+// shim directory is removed from PATH, because other tools (e.g. pyenv) may search python from PATH.
+func environWithoutShims() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if !isShimDir(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var env []string
+	for _, e := range os.Environ() {
+		if !strings.HasPrefix(strings.ToUpper(e), "PATH=") {
+			env = append(env, e)
+		}
+	}
+	return append(env, "PATH="+strings.Join(dirs, string(os.PathListSeparator)), shimDisabledEnvName+"=1")
+}
+
+func shimFileName(name string) string {
+	if runtime.GOOS == "windows" {
+		return name + ".cmd"
+	}
+	return name
+}
+
+func shimScript(pim string, name string) string {
+	if runtime.GOOS == "windows" {
+		return fmt.Sprintf("@echo off\r\nrem %s\r\n\"%s\" shim exec %s %%*\r\nexit /b %%ERRORLEVEL%%\r\n", shimMarker, pim, name)
+	}
+	return fmt.Sprintf("#!/bin/sh\n# %s\nexec \"%s\" shim exec %s \"$@\"\n", shimMarker, pim, name)
+}
+
+func isShim(path string) bool {
+	b, err := os.ReadFile(path)
+	return err == nil && strings.Contains(string(b), shimMarker)
+}
+
+// InstallShims (re)generates shims into ShimDir, and returns generated shim names.
+// shims of uninstalled minor versions are removed.
+func InstallShims(config Config) ([]string, error) {
+	pim, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		return nil, err
+	}
+
+	names := append([]string{}, shimNames...)
+	found := make(map[string]bool)
+	for _, v := range getPythonVersions(config) {
+		name := "python" + v.getMinorString()
+		if !found[name] {
+			found[name] = true
+			names = append(names, name)
+		}
+	}
+
+	generated := make(map[string]bool)
+	for _, name := range names {
+		path := filepath.Join(shimDir, shimFileName(name))
+		if err := os.WriteFile(path, []byte(shimScript(pim, name)), 0755); err != nil {
+			return nil, err
+		}
+		generated[filepath.Base(path)] = true
+	}
+
+	entries, err := os.ReadDir(shimDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(shimDir, entry.Name())
+		if !generated[entry.Name()] && isShim(path) {
+			if err := os.Remove(path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// pinnedSpecifier returns the version selected by PIM_PYTHON, .python-version or config, and where it is set.
+func pinnedSpecifier(config Config) (Specifier, string, error) {
+	if version := os.Getenv(PythonEnvName); version != "" {
+		spec, err := ParsePinnedVersion(version)
+		return spec, PythonEnvName, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Specifier{}, "", err
+	}
+	spec, path, err := FindPinnedVersion(cwd)
+	if err == nil {
+		return spec, path, nil
+	} else if !errors.Is(err, ErrPythonVersionFileNotFound) {
+		return Specifier{}, "", err
+	}
+
+	if config.DefaultPython != "" {
+		spec, err := ParsePinnedVersion(config.DefaultPython)
+		return spec, "DefaultPython", err
+	}
+	return Specifier{}, "", ErrNoPythonPinned
+}
+
+// resolveShim returns the python executable and arguments prefix for the shim.
+func resolveShim(config Config, name string) (string, []string, error) {
+	name = strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(name), ".exe"), ".cmd")
+
+	var spec Specifier
+	var err error
+	if match := minorShimRegex.FindStringSubmatch(name); match != nil {
+		if spec, err = ParsePinnedVersion(match[1]); err != nil {
+			return "", nil, err
+		}
+	} else {
+		var from string
+		if spec, from, err = pinnedSpecifier(config); err != nil {
+			return "", nil, err
+		}
+		if WithVerbose > 0 {
+			fmt.Fprintf(os.Stderr, "pim: %s (set by %s)\n", spec.String(), from)
+		}
+	}
+
+	installation, err := FindInstallation(config, spec)
+	if err != nil {
+		return "", nil, err
+	}
+	path := installation.ExecutablePath
+
+	switch name {
+	case "pip", "pip3":
+		return path, []string{"-m", "pip"}, nil
+	case "python", "python3":
+		return path, nil, nil
+	default:
+		if minorShimRegex.MatchString(name) {
+			return path, nil, nil
+		}
+		return "", nil, fmt.Errorf("unknown shim: %s", name)
+	}
+}
+
+// runPython runs python with stdin, stdout and stderr, and returns the exit code.
+func runPython(path string, args ...string) (int, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
+
+// ExecShim runs python selected by the shim name, and returns the exit code.
+func ExecShim(config Config, name string, args []string) (int, error) {
+	if os.Getenv(shimDisabledEnvName) != "" {
+		return 1, fmt.Errorf("shim %s is called while pim is detecting python. remove %s from PATH of the tool", name, shimDir)
+	}
+	path, prefix, err := resolveShim(config, name)
+	if err != nil {
+		return 1, err
+	}
+	return runPython(path, append(prefix, args...)...)
+}
+
+// ExecPython runs the newest installed python matching version (e.g. "3.11", "3.13t", ">=3.10"), and returns the exit code.
+func ExecPython(config Config, version string, args []string) (int, error) {
+	spec, err := ParsePinnedVersion(version)
+	if err != nil {
+		return 1, err
+	}
+	installation, err := FindInstallation(config, spec)
+	if err != nil {
+		return 1, err
+	}
+	if WithVerbose > 0 {
+		fmt.Fprintf(os.Stderr, "pim: run %s (%s)\n", installation.ExecutablePath, installation.Version.String())
+	}
+	return runPython(installation.ExecutablePath, args...)
+}
@@ -0,0 +1,157 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const helperProcessEnvName = "PIM_TEST_HELPER_PROCESS"
+
+// TestHelperProcess is not a test. it acts as python run by pim.
+// args after "--" are the command: "echo" writes args, "cat" copies stdin and "exit" exits with the code.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv(helperProcessEnvName) == "" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	if len(args) < 2 {
+		os.Exit(2)
+	}
+	switch args[1] {
+	case "echo":
+		_, _ = os.Stdout.WriteString(strings.Join(args[2:], " ") + "\n")
+	case "cat":
+		_, _ = io.Copy(os.Stdout, os.Stdin)
+	case "exit":
+		code, _ := strconv.Atoi(args[2])
+		os.Exit(code)
+	}
+	os.Exit(0)
+}
+
+// installHelperPython installs the test binary as standalone python of version.
+func installHelperPython(t *testing.T, version string) {
+	t.Helper()
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(self)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := standaloneExecutablePath(filepath.Join(standaloneDir, version))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecPython(t *testing.T) {
+	t.Setenv(helperProcessEnvName, "1")
+	useTempDirs(t)
+	installHelperPython(t, "3.12.7")
+	// older patch version is not run. it is broken to fail if run.
+	broken := standaloneExecutablePath(filepath.Join(standaloneDir, "3.12.1"))
+	if err := os.MkdirAll(filepath.Dir(broken), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(broken, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	helper := []string{"-test.run=^TestHelperProcess$", "--"}
+	tests := []struct {
+		name       string
+		version    string
+		args       []string
+		stdin      string
+		wantCode   int
+		wantStdout string
+		// wantErr is the error code. (see classifyError)
+		wantErr string
+	}{
+		{name: "stdout", version: "3.12", args: []string{"echo", "hello", "world"}, wantStdout: "hello world\n"},
+		{name: "stdin", version: "3.12", args: []string{"cat"}, stdin: "print(1)\n", wantStdout: "print(1)\n"},
+		{name: "exit code", version: ">=3.12", args: []string{"exit", "3"}, wantCode: 3},
+		{name: "not installed", version: "3.99", wantCode: 1, wantErr: "not_found"},
+		{name: "invalid version", version: "three", wantCode: 1, wantErr: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			stdin, err := os.Create(filepath.Join(dir, "stdin"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer deferErrCheck(stdin.Close)
+			if _, err := stdin.WriteString(tt.stdin); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			stdout, err := os.Create(filepath.Join(dir, "stdout"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer deferErrCheck(stdout.Close)
+
+			savedStdin, savedStdout := os.Stdin, os.Stdout
+			os.Stdin, os.Stdout = stdin, stdout
+			code, err := ExecPython(Config{}, tt.version, append(helper, tt.args...))
+			os.Stdin, os.Stdout = savedStdin, savedStdout
+
+			if tt.wantErr != "" {
+				if c, _ := classifyError(err); err == nil || c != tt.wantErr {
+					t.Fatalf("ExecPython error = %v, want %s", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ExecPython: %v", err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			b, err := os.ReadFile(stdout.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.wantStdout {
+				t.Errorf("stdout = %q, want %q", b, tt.wantStdout)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// specifierRegex is a clause of PEP 440 version specifier. (e.g. ">=3.11", "~=3.12.2", "==3.12.*")
+// "t" suffix selects free-threaded build. (e.g. ">=3.13t", "==3.13.*t")
+const (
+	specifierRegex = `^(?P<op>~=|===|==|!=|<=|>=|<|>)?\s*(?P<version>[^\s*]+?)(?P<wildcard>\.\*)?(?P<freeThreaded>t)?$`
+)
+
+var (
+	specifierClauseRegex = regexp.MustCompile(specifierRegex)
+)
+
+type specifierClause struct {
+	op       string
+	version  Version
+	raw      string // version string, used by "===" and String.
+	wildcard bool
+	segments int // count of release segments in raw. (e.g. "3.12" -> 2)
+}
+
+// Specifier is PEP 440 version specifier, like ">=3.11,<3.13".
+// all clauses must match.
+type Specifier struct {
+	clauses []specifierClause
+}
+
+func releaseSegments(versionString string) int {
+	release := strings.TrimPrefix(strings.ToLower(versionString), "v")
+	if i := strings.Index(release, "!"); i >= 0 {
+		release = release[i+1:]
+	}
+	if i := strings.IndexFunc(release, func(r rune) bool { return r != '.' && (r < '0' || '9' < r) }); i >= 0 {
+		release = release[:i]
+	}
+	return len(strings.Split(strings.TrimSuffix(release, "."), "."))
+}
+
+// ParseSpecifier parses comma separated PEP 440 version specifier.
+// clause without operator is treated as "==".
+func ParseSpecifier(specifierString string) (Specifier, error) {
+	var spec Specifier
+	for _, part := range strings.Split(specifierString, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return Specifier{}, fmt.Errorf("invalid specifier (empty clause): %s", specifierString)
+		}
+
+		matches := specifierClauseRegex.FindStringSubmatch(part)
+		if matches == nil {
+			return Specifier{}, fmt.Errorf("invalid specifier (not match): %s", part)
+		}
+
+		clause := specifierClause{
+			op:       matches[specifierClauseRegex.SubexpIndex("op")],
+			raw:      matches[specifierClauseRegex.SubexpIndex("version")],
+			wildcard: matches[specifierClauseRegex.SubexpIndex("wildcard")] != "",
+		}
+		if clause.op == "" {
+			clause.op = "=="
+		}
+		clause.segments = releaseSegments(clause.raw)
+		freeThreaded := matches[specifierClauseRegex.SubexpIndex("freeThreaded")] != ""
+
+		if clause.op == "===" {
+			spec.clauses = append(spec.clauses, clause)
+			continue
+		}
+
+		v, err := NewVersion(clause.raw)
+		if err != nil {
+			return Specifier{}, fmt.Errorf("invalid specifier (version): %s: %w", part, err)
+		}
+		v.FreeThreaded = v.FreeThreaded || freeThreaded
+		clause.version = v
+
+		if clause.wildcard && clause.op != "==" && clause.op != "!=" {
+			return Specifier{}, fmt.Errorf("invalid specifier (wildcard is allowed only with == and !=): %s", part)
+		}
+		if clause.wildcard && (v.IsPreRelease() || v.Post || v.Local != "") {
+			return Specifier{}, fmt.Errorf("invalid specifier (wildcard is allowed only after release segments): %s", part)
+		}
+		if clause.op == "~=" && clause.segments < 2 {
+			return Specifier{}, fmt.Errorf("invalid specifier (~= needs at least 2 release segments): %s", part)
+		}
+		if v.Local != "" && clause.op != "==" && clause.op != "!=" {
+			return Specifier{}, fmt.Errorf("invalid specifier (local version is allowed only with == and !=): %s", part)
+		}
+
+		spec.clauses = append(spec.clauses, clause)
+	}
+	return spec, nil
+}
+
+// NewExactSpecifier returns specifier which matches only version.
+func NewExactSpecifier(version Version) Specifier {
+	raw := version
+	raw.FreeThreaded = false
+	return Specifier{[]specifierClause{{op: "==", version: version, raw: raw.String(), segments: 3}}}
+}
+
+// NewMinorSpecifier returns specifier which matches all versions of the minor version. ("==Major.Minor.*")
+func NewMinorSpecifier(version Version) Specifier {
+	raw := fmt.Sprintf("%d.%d", version.Major, version.Minor)
+	v, _ := NewVersion(raw)
+	v.FreeThreaded = version.FreeThreaded
+	return Specifier{[]specifierClause{{op: "==", version: v, raw: raw, wildcard: true, segments: 2}}}
+}
+
+func releaseOf(v Version) []int {
+	return []int{v.Major, v.Minor, v.Micro}
+}
+
+// matchPrefix returns true if the first n release segments of v and o are same.
+func matchPrefix(v Version, o Version, n int) bool {
+	if v.Epoch != o.Epoch {
+		return false
+	}
+	vr, or := releaseOf(v), releaseOf(o)
+	for i := 0; i < n && i < len(vr); i++ {
+		if vr[i] != or[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c specifierClause) match(v Version) bool {
+	if c.op == "===" {
+		return strings.EqualFold(v.String(), c.raw)
+	}
+
+	// free-threaded is a build flavour, not a part of the version.
+	v.FreeThreaded = false
+	c.version.FreeThreaded = false
+	// local label of candidate is ignored if specifier has no local label.
+	if c.version.Local == "" {
+		v.Local = ""
+	}
+
+	switch c.op {
+	case "==":
+		if c.wildcard {
+			return matchPrefix(v, c.version, c.segments)
+		}
+		return v.Equal(c.version)
+	case "!=":
+		if c.wildcard {
+			return !matchPrefix(v, c.version, c.segments)
+		}
+		return !v.Equal(c.version)
+	case "~=":
+		return v.GreaterThanOrEqual(c.version) && matchPrefix(v, c.version, c.segments-1)
+	case "<=":
+		return v.LessThanOrEqual(c.version)
+	case ">=":
+		return v.GreaterThanOrEqual(c.version)
+	case "<":
+		// "<V" does not match pre-releases of V, unless V is pre-release.
+		if !c.version.IsPreRelease() && v.IsPreRelease() && matchPrefix(v, c.version, 3) {
+			return false
+		}
+		return v.LessThan(c.version)
+	case ">":
+		// ">V" does not match post-releases of V, unless V is post-release.
+		if !c.version.Post && v.Post && matchPrefix(v, c.version, 3) && v.Pre == c.version.Pre && v.PreNum == c.version.PreNum {
+			return false
+		}
+		return v.GreaterThan(c.version)
+	default:
+		panic("UNREACHABLE: invalid specifier operator")
+	}
+}
+
+// Match returns true if v matches all clauses.
+func (s Specifier) Match(v Version) bool {
+	for _, c := range s.clauses {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsPreRelease returns true if specifier explicitly mentions pre-release. (e.g. ">=3.13.0rc1")
+func (s Specifier) AllowsPreRelease() bool {
+	for _, c := range s.clauses {
+		if c.op != "!=" && c.version.IsPreRelease() {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeThreaded returns true if specifier selects free-threaded build.
+func (s Specifier) FreeThreaded() bool {
+	for _, c := range s.clauses {
+		if c.version.FreeThreaded {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExact returns true if specifier pins a version. (e.g. "==3.12.1")
+func (s Specifier) IsExact() bool {
+	return len(s.clauses) == 1 && (s.clauses[0].op == "==" || s.clauses[0].op == "===") && !s.clauses[0].wildcard
+}
+
+func (s Specifier) String() string {
+	clauses := make([]string, 0, len(s.clauses))
+	for _, c := range s.clauses {
+		str := c.op + c.raw
+		if c.wildcard {
+			str += ".*"
+		}
+		if c.version.FreeThreaded {
+			str += "t"
+		}
+		clauses = append(clauses, str)
+	}
+	return strings.Join(clauses, ",")
+}
+
+var _ fmt.Stringer = Specifier{}
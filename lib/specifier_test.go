@@ -0,0 +1,138 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import "testing"
+
+func TestSpecifierMatch(t *testing.T) {
+	tests := []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		{"==3.13.1t", "3.13.1t", true},
+		{"==3.13.1t", "3.13.1", true}, // free-threaded is selected by FreeThreaded, not by Match.
+		{"==3.13.1t", "3.13.2t", false},
+		{"3.12.1", "3.12.1", true},
+		{"==3.12.1", "3.12.1+local", true},
+		{"==3.12.1+local", "3.12.1", false},
+		{"==3.12.*", "3.12.0", true},
+		{"==3.12.*", "3.12.9", true},
+		{"==3.12.*", "3.12.0rc1", true},
+		{"==3.12.*", "3.13.0", false},
+		{"==3.12.*", "3.1.0", false},
+		{"!=3.12.*", "3.12.4", false},
+		{"!=3.12.*", "3.11.4", true},
+		{"~=3.12.1", "3.12.1", true},
+		{"~=3.12.1", "3.12.9", true},
+		{"~=3.12.1", "3.12.0", false},
+		{"~=3.12.1", "3.13.0", false},
+		{"~=3.12", "3.13.0", true},
+		{"~=3.12", "4.0.0", false},
+		{"!=3.12.1", "3.12.1", false},
+		{"!=3.12.1", "3.12.2", true},
+		{"<3.13", "3.12.7", true},
+		{"<3.13", "3.13.0rc1", false},
+		{"<3.13", "3.13.0", false},
+		{"<3.13.0rc2", "3.13.0rc1", true},
+		{"<=3.13", "3.13.0", true},
+		{">3.12.0", "3.12.0.post1", false},
+		{">3.12.0", "3.12.1", true},
+		{">3.12.0.post1", "3.12.0.post2", true},
+		{">=3.11,<3.13", "3.11.0", true},
+		{">=3.11,<3.13", "3.13.1", false},
+		{"===3.12.1", "3.12.1", true},
+		{"===3.12.1", "3.12.1.post0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+"/"+tt.version, func(t *testing.T) {
+			spec, err := ParseSpecifier(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSpecifier(%q) returns error: %v", tt.spec, err)
+			}
+			v, err := NewVersion(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := spec.Match(v); got != tt.want {
+				t.Errorf("%q.Match(%s) = %v, want %v", tt.spec, tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpecifier(t *testing.T) {
+	tests := []struct {
+		spec         string
+		want         string
+		freeThreaded bool
+		exact        bool
+		preRelease   bool
+	}{
+		{"3.12", "==3.12", false, true, false},
+		{"==3.13.1t", "==3.13.1t", true, true, false},
+		{"==3.13.*t", "==3.13.*t", true, false, false},
+		{" >=3.11 , <3.13 ", ">=3.11,<3.13", false, false, false},
+		{">=3.13.0rc1", ">=3.13.0rc1", false, false, true},
+		{"!=3.13.0rc1", "!=3.13.0rc1", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			spec, err := ParseSpecifier(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSpecifier(%q) returns error: %v", tt.spec, err)
+			}
+			if spec.String() != tt.want {
+				t.Errorf("String() = %q, want %q", spec.String(), tt.want)
+			}
+			if spec.FreeThreaded() != tt.freeThreaded {
+				t.Errorf("FreeThreaded() = %v, want %v", spec.FreeThreaded(), tt.freeThreaded)
+			}
+			if spec.IsExact() != tt.exact {
+				t.Errorf("IsExact() = %v, want %v", spec.IsExact(), tt.exact)
+			}
+			if spec.AllowsPreRelease() != tt.preRelease {
+				t.Errorf("AllowsPreRelease() = %v, want %v", spec.AllowsPreRelease(), tt.preRelease)
+			}
+		})
+	}
+}
+
+func TestParseSpecifierInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		">=3.11,",
+		"=>3.11",
+		"python",
+		">=3.12.*",
+		"==3.12.0rc1.*",
+		"~=3",
+		">=3.12+local",
+	} {
+		if _, err := ParseSpecifier(spec); err == nil {
+			t.Errorf("ParseSpecifier(%q) returns no error", spec)
+		}
+	}
+}
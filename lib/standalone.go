@@ -0,0 +1,265 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// python-build-standalone (https://github.com/astral-sh/python-build-standalone)
+// example (python 3.12.7, release 20241016)
+// - install_only: {base}/20241016/cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-install_only.tar.gz
+// - full: {base}/20241016/cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-pgo+lto-full.tar.zst
+// install_only archive has "python/" directory, full archive has "python/install/" directory.
+const (
+	DefaultStandaloneBaseUrl = "https://github.com/astral-sh/python-build-standalone/releases/download"
+	standaloneLatestUrl      = "https://api.github.com/repos/astral-sh/python-build-standalone/releases/latest"
+	standaloneFileNameBase   = `cpython-%s+%s-%s-%s`
+	standaloneInstallOnly    = "install_only"
+	// free-threaded build is provided only as full archive.
+	standaloneFreeThreadedFlavor        = "freethreaded+pgo+lto-full"
+	standaloneFreeThreadedWindowsFlavor = "freethreaded+pgo-full"
+)
+
+var (
+	standaloneDir string
+)
+
+func init() {
+	standaloneDir = filepath.Join(dataDir, "standalone")
+}
+
+func standaloneTriple(goarch string) (string, error) {
+	arch := map[string]string{"amd64": "x86_64", "arm64": "aarch64"}[goarch]
+	if arch == "" {
+		return "", fmt.Errorf("unsupported arch for python-build-standalone: %s", goarch)
+	}
+	switch runtime.GOOS {
+	case "linux":
+		return arch + "-unknown-linux-gnu", nil
+	case "windows":
+		return arch + "-pc-windows-msvc", nil
+	case "darwin":
+		return arch + "-apple-darwin", nil
+	default:
+		return "", fmt.Errorf("unsupported os for python-build-standalone: %s", runtime.GOOS)
+	}
+}
+
+func standaloneRelease(ctx context.Context, config Config) (string, error) {
+	if config.StandaloneRelease != "" {
+		return config.StandaloneRelease, nil
+	}
+	if config.Offline {
+		return cachedStandaloneRelease()
+	}
+
+	req, err := newGitHubRequest(ctx, config, standaloneLatestUrl)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpDo(config, req)
+	if err != nil {
+		return "", err
+	}
+	defer deferErrCheck(resp.Body.Close)
+	if err := checkGitHubResponse(resp, http.StatusOK); err != nil {
+		return "", fmt.Errorf("failed to detect latest python-build-standalone release: %w", err)
+	}
+	byteArray, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(byteArray, &release); err != nil {
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+// cachedStandaloneRelease returns the newest release of the cached archives for this platform.
+func cachedStandaloneRelease() (string, error) {
+	triple, err := standaloneTriple(runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	paths, _ := filepath.Glob(filepath.Join(installerCacheDir, fmt.Sprintf(standaloneFileNameBase, "*", "*", triple, "*")))
+	release := ""
+	for _, path := range paths {
+		// cpython-<version>+<release>-<triple>-<flavor>
+		_, rest, _ := strings.Cut(filepath.Base(path), "+")
+		if r, _, ok := strings.Cut(rest, "-"); ok && r > release {
+			release = r
+		}
+	}
+	if release == "" {
+		return "", &OfflineError{"python-build-standalone release"}
+	}
+	return release, nil
+}
+
+func standaloneArtifact(config Config, version Version, arch string, release string) (Artifact, error) {
+	triple, err := standaloneTriple(arch)
+	if err != nil {
+		return Artifact{}, err
+	}
+	flavor := config.StandaloneFlavor
+	if flavor == "" {
+		flavor = standaloneInstallOnly
+	}
+	if version.FreeThreaded {
+		flavor = standaloneFreeThreadedFlavor
+		if strings.HasSuffix(triple, "-windows-msvc") {
+			flavor = standaloneFreeThreadedWindowsFlavor
+		}
+	}
+	baseUrl := config.StandaloneBaseUrl
+	if baseUrl == "" {
+		baseUrl = DefaultStandaloneBaseUrl
+	}
+
+	artifact := Artifact{Kind: ArtifactArchive, Root: "python/install/", Verification: VerifyStandaloneSums}
+	artifact.FileName = fmt.Sprintf(standaloneFileNameBase, version.getFullString(), release, triple, flavor) + ".tar.zst"
+	if flavor == standaloneInstallOnly {
+		artifact.FileName = fmt.Sprintf(standaloneFileNameBase, version.getFullString(), release, triple, flavor) + ".tar.gz"
+		artifact.Root = "python/"
+	}
+	artifact.Url = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(baseUrl, "/"), release, artifact.FileName)
+	return artifact, nil
+}
+
+func standaloneRoot(config Config) string {
+	if config.TargetDirectory != "" {
+		return config.TargetDirectory
+	}
+	return standaloneDir
+}
+
+func standaloneExecutablePath(dir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(dir, "python.exe")
+	}
+	return filepath.Join(dir, "bin", "python3")
+}
+
+func extractStandalone(config Config, version Version, path string, root string) error {
+	dest := filepath.Join(standaloneRoot(config), version.String())
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("python %s is already installed: %s", version.String(), dest)
+	}
+
+	// extract into temporary directory, so broken directory is not left.
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return err
+	}
+	if err := extractArchive(path, tmp, root); err != nil {
+		deferErrCheck(func() error { return os.RemoveAll(tmp) })
+		return err
+	}
+	return os.Rename(tmp, dest)
+}
+
+func removeOldStandalone(config Config, version Version) error {
+	for _, v := range getStandalonePythonVersions(config) {
+		if newInstallKey(v) == newInstallKey(version) && v.LessThan(version) {
+			if err := os.RemoveAll(filepath.Join(standaloneRoot(config), v.String())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func uninstallStandalone(config Config, version Version) error {
+	removed := false
+	for _, v := range getStandalonePythonVersions(config) {
+		if v.Major == version.Major && newInstallKey(v) == newInstallKey(version) {
+			if err := os.RemoveAll(filepath.Join(standaloneRoot(config), v.String())); err != nil {
+				return err
+			}
+			removed = true
+		}
+	}
+	if !removed {
+		return fmt.Errorf("python %s is not installed by pim: %s", version.String(), standaloneRoot(config))
+	}
+	return nil
+}
+
+func getStandaloneInstallations(config Config) []Installation {
+	var installations []Installation
+
+	entries, err := os.ReadDir(standaloneRoot(config))
+	if err != nil {
+		return installations
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		v, err := NewVersion(entry.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(standaloneRoot(config), entry.Name())
+		if _, err := os.Stat(standaloneExecutablePath(dir)); err != nil {
+			continue
+		}
+		installations = append(installations, Installation{
+			Version:        v,
+			DisplayName:    fmt.Sprintf("Python %s (python-build-standalone)", v.String()),
+			Company:        CompanyStandalone,
+			DirectoryPath:  dir,
+			ExecutablePath: standaloneExecutablePath(dir),
+			Scope:          ScopePerUser,
+			Arch:           runtime.GOARCH,
+			Distribution:   DistributionStandalone,
+		})
+	}
+	return installations
+}
+
+func getStandalonePythonVersions(config Config) []Version {
+	var versions []Version
+	for _, installation := range getStandaloneInstallations(config) {
+		versions = append(versions, installation.Version)
+	}
+	return versions
+}
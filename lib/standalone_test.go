@@ -0,0 +1,170 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fileServer serves files by url path. other paths are 404.
+type fileServer struct {
+	mu       sync.Mutex
+	files    map[string][]byte
+	requests []string
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	b, ok := s.files[r.URL.Path]
+	s.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(b)
+}
+
+const testStandaloneRelease = "20241016"
+
+// standaloneArchive returns a python-build-standalone archive of version for current platform, and its sha256.
+func standaloneArchive(t *testing.T, config Config, version Version) (Artifact, []byte, string) {
+	t.Helper()
+	artifact, err := standaloneArtifact(config, version, runtime.GOARCH, testStandaloneRelease)
+	if err != nil {
+		t.Skip(err)
+	}
+	archive := writeTestArchive(t, tarFile(artifact.Root+filepath.ToSlash(standaloneExecutablePath("")), "python"))
+	return artifact, archive, sha256Hex(archive)
+}
+
+func TestStandaloneInstall(t *testing.T) {
+	version, _ := NewVersion("3.12.7")
+	config := Config{Distribution: DistributionStandalone, StandaloneRelease: testStandaloneRelease}
+
+	tests := []struct {
+		name string
+		// sums returns SHA256SUMS of the release. nil for 404.
+		sums      func(fileName string, sha string) []byte
+		serveFile bool
+		check     func(t *testing.T, err error)
+	}{
+		{
+			name:      "verified by SHA256SUMS",
+			sums:      func(fileName string, sha string) []byte { return []byte(sha + "  " + fileName + "\n") },
+			serveFile: true,
+			check: func(t *testing.T, err error) {
+				if err != nil {
+					t.Fatalf("doInstall: %v", err)
+				}
+				installations := getStandaloneInstallations(config)
+				if len(installations) != 1 || installations[0].Version != version || installations[0].Company != CompanyStandalone {
+					t.Fatalf("installations = %v", installations)
+				}
+				if err := doUninstall(context.Background(), config, nil, installations[0]); err != nil {
+					t.Fatalf("doUninstall: %v", err)
+				}
+				if installations := getStandaloneInstallations(config); len(installations) != 0 {
+					t.Errorf("installations after uninstall = %v", installations)
+				}
+			},
+		},
+		{
+			name: "sha256 mismatch",
+			sums: func(fileName string, sha string) []byte {
+				return []byte(strings.Repeat("0", 64) + "  " + fileName + "\n")
+			},
+			serveFile: true,
+			check: func(t *testing.T, err error) {
+				var vErr *VerificationError
+				if !errors.As(err, &vErr) {
+					t.Fatalf("doInstall error = %v, want VerificationError", err)
+				}
+				if _, err := os.Stat(vErr.Quarantine); err != nil {
+					t.Errorf("archive is not quarantined: %v", err)
+				}
+				if installations := getStandaloneInstallations(config); len(installations) != 0 {
+					t.Errorf("installations = %v", installations)
+				}
+			},
+		},
+		{
+			name: "archive not found",
+			check: func(t *testing.T, err error) {
+				var sErr *StatusError
+				if !errors.As(err, &sErr) || sErr.Status != http.StatusNotFound {
+					t.Fatalf("doInstall error = %v, want 404", err)
+				}
+				// archive is only for the newest patch of each release, so missing one is not recorded.
+				if v, ok := failedMinimumVersions[version.Minor]; ok {
+					t.Errorf("failed version is recorded: %s", v.String())
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempDirs(t)
+			failedMinimumVersions = make(map[int]Version)
+			server := &fileServer{files: make(map[string][]byte)}
+			base := useTestServer(t, server).URL + "/download"
+			config := config
+			config.StandaloneBaseUrl = base
+
+			artifact, archive, sha := standaloneArchive(t, config, version)
+			if tt.serveFile {
+				server.files["/download/"+testStandaloneRelease+"/"+artifact.FileName] = archive
+			}
+			if tt.sums != nil {
+				server.files["/download/"+testStandaloneRelease+"/"+standaloneSumsFileName] = tt.sums(artifact.FileName, sha)
+			}
+			if artifact.Url != base+"/"+testStandaloneRelease+"/"+artifact.FileName {
+				t.Fatalf("artifact url = %s, not in StandaloneBaseUrl", artifact.Url)
+			}
+
+			provider, err := NewProvider(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = doInstall(context.Background(), config, provider, []Version{version})
+			tt.check(t, err)
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			for _, request := range server.requests {
+				if !strings.HasPrefix(request, "/download/") {
+					t.Errorf("request outside of StandaloneBaseUrl: %s", request)
+				}
+			}
+		})
+	}
+}
@@ -25,26 +25,113 @@ SOFTWARE.
 package lib
 
 import (
+	"context"
 	"fmt"
 	"github.com/hawk-tomy/pim/lib/list"
 	"sort"
 )
 
+// installKey identifies an installation. installations of the same minor can be installed side by side:
+// free-threaded build, other arch (e.g. 32bit on 64bit windows), other scope (for all users and for current user)
+// and other distribution.
+type installKey struct {
+	Minor        int
+	FreeThreaded bool
+	Arch         string
+	Scope        string
+	Distribution string
+}
+
+// newInstallKey returns the key of version. arch, scope and distribution are empty, so it compares versions only.
+func newInstallKey(v Version) installKey {
+	return installKey{Minor: v.Minor, FreeThreaded: v.FreeThreaded}
+}
+
+func (i Installation) key() installKey {
+	return installKey{i.Version.Minor, i.Version.FreeThreaded, i.Arch, i.Scope, i.Distribution}
+}
+
+// variantOf returns v as the build of k.
+func (k installKey) variantOf(v Version) Version {
+	v.FreeThreaded = k.FreeThreaded
+	return v
+}
+
+// configOf returns config to install the build of i. (same distribution, scope and directory as installed one)
+func (i Installation) configOf(config Config) Config {
+	config.Distribution = i.Distribution
+	config.ForAllUser = i.Scope == ScopeAllUser
+	if dir := installationTargetDirectory(i); dir != "" {
+		config.TargetDirectory = dir
+	}
+	return config
+}
+
+func (k installKey) Less(o installKey) bool {
+	if k.Minor != o.Minor {
+		return k.Minor < o.Minor
+	}
+	if k.FreeThreaded != o.FreeThreaded {
+		return !k.FreeThreaded
+	}
+	if k.Distribution != o.Distribution {
+		return k.Distribution < o.Distribution
+	}
+	if k.Arch != o.Arch {
+		return k.Arch < o.Arch
+	}
+	return k.Scope < o.Scope
+}
+
+// InstallationFilter selects installations of the same minor installed side by side. empty field matches all.
+type InstallationFilter struct {
+	Arch  string
+	Scope string
+}
+
+func (f InstallationFilter) validate() error {
+	switch f.Scope {
+	case "", ScopeAllUser, ScopePerUser:
+		return nil
+	}
+	return &UsageError{fmt.Errorf("unknown scope: %s (available: %s, %s)", f.Scope, ScopeAllUser, ScopePerUser)}
+}
+
+func (f InstallationFilter) match(key installKey) bool {
+	return (f.Arch == "" || f.Arch == key.Arch) && (f.Scope == "" || f.Scope == key.Scope)
+}
+
 var (
-	installedPythonVersions map[int]Version
-	updatablePythonVersions map[int]*list.Element[Version]
+	installedPythonVersions map[installKey]Version
+	installedInstallations  map[installKey]Installation
+	updatablePythonVersions map[installKey]*list.Element[Version]
 )
 
+// getInstalledPythonVersions collects installations managed by pim. (see Installation.Managed)
 func getInstalledPythonVersions(config Config) error {
-	installedPythonVersions = make(map[int]Version)
-	for _, v := range getPythonVersions(config) {
-		installedPythonVersions[v.Minor] = v
+	installedPythonVersions = make(map[installKey]Version)
+	installedInstallations = make(map[installKey]Installation)
+	for _, installation := range getInstallations(config) {
+		if !installation.Managed() {
+			continue
+		}
+		installedPythonVersions[installation.key()] = installation.Version
+		installedInstallations[installation.key()] = installation
 	}
 	return nil
 }
 
-func detectUpdatablePythonVersions(config Config) error {
-	if err := fetchLatestVersions(config); err != nil {
+func sortedInstallKeys[T any](m map[installKey]T) []installKey {
+	keys := make([]installKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Less(keys[j]) })
+	return keys
+}
+
+func detectUpdatablePythonVersions(ctx context.Context, config Config, provider Provider) error {
+	if err := fetchLatestVersions(ctx, config, provider); err != nil {
 		return err
 	}
 
@@ -52,8 +139,8 @@ func detectUpdatablePythonVersions(config Config) error {
 		return err
 	}
 
-	updatablePythonVersions = make(map[int]*list.Element[Version])
-	for _, installedVersion := range installedPythonVersions {
+	updatablePythonVersions = make(map[installKey]*list.Element[Version])
+	for key, installedVersion := range installedPythonVersions {
 		// same minor version and installed version is old version
 		if v, ok := fetchedVersions[installedVersion.Minor]; ok {
 			var v_ *Version
@@ -77,33 +164,149 @@ func detectUpdatablePythonVersions(config Config) error {
 			if ver == nil {
 				continue
 			}
-			updatablePythonVersions[installedVersion.Minor] = ver
+			updatablePythonVersions[key] = ver
 		}
 	}
 
 	return nil
 }
 
-func StatusCommand(config Config) error {
-	if err := detectUpdatablePythonVersions(config); err != nil {
+// companyRank orders companies: managed ones, others, and unknown.
+func companyRank(company string) int {
+	switch company {
+	case CompanyPythonCore:
+		return 0
+	case CompanyStandalone:
+		return 1
+	case "":
+		return 3
+	}
+	return 2
+}
+
+// sortInstallations sorts installations by company, then version.
+func sortInstallations(installations []Installation) {
+	sort.SliceStable(installations, func(i, j int) bool {
+		a, b := installations[i], installations[j]
+		if a.Company != b.Company {
+			if companyRank(a.Company) != companyRank(b.Company) {
+				return companyRank(a.Company) < companyRank(b.Company)
+			}
+			return a.Company < b.Company
+		}
+		if newInstallKey(a.Version) != newInstallKey(b.Version) {
+			return newInstallKey(a.Version).Less(newInstallKey(b.Version))
+		}
+		return a.Version.LessThan(b.Version)
+	})
+}
+
+// updatableVersion returns the version which the installation can be updated to.
+func updatableVersion(installation Installation) (Version, bool) {
+	key := installation.key()
+	managed, ok := installedInstallations[key]
+	if !ok || managed.ExecutablePath != installation.ExecutablePath {
+		return Version{}, false
+	}
+	if ver, ok := updatablePythonVersions[key]; ok {
+		return key.variantOf(ver.Value), true
+	}
+	return Version{}, false
+}
+
+// sideBySideLabel returns " (arch, scope)" if other installation of the same minor is in installations.
+func sideBySideLabel(installations []Installation, installation Installation) string {
+	for _, other := range installations {
+		if other.Company == installation.Company &&
+			newInstallKey(other.Version) == newInstallKey(installation.Version) &&
+			(other.Arch != installation.Arch || other.Scope != installation.Scope) {
+			return fmt.Sprintf(" (%s, %s)", installation.Arch, installation.Scope)
+		}
+	}
+	return ""
+}
+
+// StatusCommand shows installations grouped by company. if company is not empty, only the company is shown.
+func StatusCommand(ctx context.Context, config Config, provider Provider, company string) error {
+	if err := detectUpdatablePythonVersions(ctx, config, provider); err != nil {
 		return err
 	}
 
-	MinorVersions := make([]int, 0, len(installedPythonVersions))
-	for k := range installedPythonVersions {
-		MinorVersions = append(MinorVersions, k)
+	var installations []Installation
+	for _, installation := range getInstallations(config) {
+		if installation.MatchCompany(company) {
+			installations = append(installations, installation)
+		}
+	}
+	sortInstallations(installations)
+
+	if IsStructuredOutput() {
+		doc := StatusDocument{Header: newHeader("status"), Installations: []StatusEntry{}}
+		for _, installation := range installations {
+			entry := StatusEntry{InstallationDocument: installation.Document()}
+			if ver, ok := updatableVersion(installation); ok {
+				entry.Updatable = ver.String()
+			}
+			doc.Installations = append(doc.Installations, entry)
+		}
+		return WriteDocument(doc)
+	}
+
+	fmt.Fprintln(MessageOut(), "Installed Python versions:")
+	for i, installation := range installations {
+		if i == 0 || installation.Company != installations[i-1].Company {
+			name := installation.Company
+			if name == "" {
+				name = "unknown"
+			}
+			if companyRank(installation.Company) > 1 {
+				name += " (not managed by pim)"
+			}
+			fmt.Fprintf(MessageOut(), "%s:\n", name)
+		}
+
+		statusStr := installation.Version.String()
+		if installation.Version.FreeThreaded {
+			statusStr += " (free-threaded)"
+		}
+		statusStr += sideBySideLabel(installations, installation)
+		if installation.Tag != "" {
+			statusStr += fmt.Sprintf(" [%s]", installation.Tag)
+		}
+		if ver, ok := updatableVersion(installation); ok {
+			statusStr += fmt.Sprintf(" (updatable: %s)", ver.String())
+		} else if companyRank(installation.Company) <= 1 && !installation.Managed() {
+			// e.g. microsoft store
+			statusStr += " (not managed by pim)"
+		}
+		fmt.Fprintf(MessageOut(), "  %s\n", statusStr)
+	}
+
+	return nil
+}
+
+// ListCommand shows installations. if company is not empty, only the company is shown.
+func ListCommand(config Config, company string) error {
+	var installations []Installation
+	for _, installation := range getInstallations(config) {
+		if installation.MatchCompany(company) {
+			installations = append(installations, installation)
+		}
 	}
-	sort.Ints(MinorVersions)
+	sort.SliceStable(installations, func(i, j int) bool {
+		return installations[i].Version.LessThan(installations[j].Version)
+	})
 
-	fmt.Println("Installed Python versions:")
-	for _, minor := range MinorVersions {
-		v := installedPythonVersions[minor]
-		statusStr := v.String()
-		if ver, ok := updatablePythonVersions[minor]; ok {
-			statusStr += fmt.Sprintf(" (updatable: %s)", ver.Value.String())
+	if IsStructuredOutput() {
+		doc := ListDocument{Header: newHeader("list"), Installations: []InstallationDocument{}}
+		for _, installation := range installations {
+			doc.Installations = append(doc.Installations, installation.Document())
 		}
-		fmt.Println(statusStr)
+		return WriteDocument(doc)
 	}
 
+	for _, installation := range installations {
+		fmt.Fprintf(MessageOut(), "%-12s %-10s %s\n", installation.Version.String(), installation.Distribution, installation.ExecutablePath)
+	}
 	return nil
 }
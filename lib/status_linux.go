@@ -0,0 +1,193 @@
+//go:build linux
+
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var (
+	pythonExecutableRegex = regexp.MustCompile(`^python\d+\.\d+t?$`)
+)
+
+// isScript returns true if path is a script (starts with "#!").
+// scripts are wrappers of other tools (e.g. shims of pyenv), not python itself.
+func isScript(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer deferErrCheck(f.Close)
+
+	head := make([]byte, 2)
+	n, _ := f.Read(head)
+	return n == 2 && string(head) == "#!"
+}
+
+// findPythonExecutables returns "pythonX.Y" and "pythonX.Yt" (free-threaded) executables in dirs.
+func findPythonExecutables(dirs []string) []string {
+	var paths []string
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if pythonExecutableRegex.MatchString(entry.Name()) {
+				paths = append(paths, filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	return paths
+}
+
+// installationScope returns scope of python installed into prefix.
+func installationScope(prefix string) string {
+	if isUnder(prefix, allUserInstallDir) {
+		return ScopeAllUser
+	}
+	if home, err := os.UserHomeDir(); err == nil && isUnder(prefix, home) {
+		return ScopePerUser
+	}
+	return ScopeAllUser
+}
+
+// nativeInstallRoots returns directories which prefixes are installed into. (see installPrefix)
+func nativeInstallRoots(config Config) []string {
+	roots := []string{userInstallDir, allUserInstallDir}
+	if config.TargetDirectory != "" {
+		roots = append([]string{config.TargetDirectory}, roots...)
+	}
+	return roots
+}
+
+func nativeInstallation(prefix string, path string, v Version) Installation {
+	return Installation{
+		Version:        v,
+		DisplayName:    fmt.Sprintf("Python %s", v.String()),
+		DirectoryPath:  prefix,
+		ExecutablePath: path,
+		Scope:          installationScope(prefix),
+		Arch:           runtime.GOARCH,
+		Distribution:   DistributionNative,
+	}
+}
+
+// getPimInstallations returns python installed by pim. version is read from the marker, without running python.
+func getPimInstallations(config Config) []Installation {
+	var installations []Installation
+	found := make(map[string]bool)
+	for _, root := range nativeInstallRoots(config) {
+		markers, _ := filepath.Glob(filepath.Join(root, "*", prefixMarkerName))
+		for _, marker := range markers {
+			prefix := filepath.Dir(marker)
+			b, err := os.ReadFile(marker)
+			if err != nil || found[prefix] {
+				continue
+			}
+			v, err := NewVersion(strings.TrimSpace(string(b)))
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(prefix, "bin", "python"+v.getMinorString())
+			if !isFile(path) {
+				continue
+			}
+			found[prefix] = true
+			installation := nativeInstallation(prefix, path, v)
+			// built from source code of python.org
+			installation.Company = CompanyPythonCore
+			installations = append(installations, installation)
+		}
+	}
+	return installations
+}
+
+func getNativeInstallations(config Config) []Installation {
+	var installations []Installation
+	foundPath := make(map[string]bool)
+	foundKey := make(map[installKey]bool)
+
+	// installed by pim
+	for _, installation := range getPimInstallations(config) {
+		if realPath, err := filepath.EvalSymlinks(installation.ExecutablePath); err == nil {
+			foundPath[realPath] = true
+		}
+		foundKey[installation.key()] = true
+		installations = append(installations, installation)
+	}
+
+	var dirs []string
+	for _, root := range nativeInstallRoots(config) {
+		prefixes, _ := filepath.Glob(filepath.Join(root, "*", "bin"))
+		dirs = append(dirs, prefixes...)
+	}
+	// installed by others
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		// shims call pim itself.
+		if isShimDir(dir) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	for _, path := range findPythonExecutables(dirs) {
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil || foundPath[realPath] || isScript(realPath) {
+			continue
+		}
+
+		v, err := readVersionFromExecutable(path)
+		if err != nil {
+			continue
+		}
+		// "--version" of free-threaded build does not have "t".
+		v.FreeThreaded = strings.HasSuffix(path, "t")
+		// skip alias of other version, like "python3.1" -> "python3.13".
+		if filepath.Base(path) != "python"+v.getMinorString() {
+			continue
+		}
+		foundPath[realPath] = true
+
+		// executable is placed in "<prefix>/bin".
+		installation := nativeInstallation(filepath.Dir(filepath.Dir(path)), path, v)
+		// prefer installed by pim, which is found at first.
+		// the same minor in other scope (e.g. "/opt/pim" and "~/.local") is kept.
+		if foundKey[installation.key()] {
+			continue
+		}
+		foundKey[installation.key()] = true
+		installations = append(installations, installation)
+	}
+
+	return installations
+}
@@ -24,30 +24,74 @@ SOFTWARE.
 
 package lib
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
 
-func getInstalledPythonByMinor(config Config, version Version) (Version, error) {
+// findManagedInstallation returns the installation of the minor of version selected by filter.
+// if installations are side by side in other distributions, the configured distribution is preferred.
+func findManagedInstallation(config Config, version Version, filter InstallationFilter) (Installation, error) {
 	err := getInstalledPythonVersions(config)
 	if err != nil {
-		return Version{}, err
+		return Installation{}, err
 	}
-	for _, v := range installedPythonVersions {
-		if v.Major == version.Major && v.Minor == version.Minor {
-			return v, nil
+	var found []Installation
+	for _, key := range sortedInstallKeys(installedInstallations) {
+		installation := installedInstallations[key]
+		v := installation.Version
+		if v.Major == version.Major && v.Minor == version.Minor && v.FreeThreaded == version.FreeThreaded && filter.match(key) {
+			found = append(found, installation)
+		}
+	}
+	if len(found) > 1 {
+		if d, err := distribution(config); err == nil {
+			var preferred []Installation
+			for _, installation := range found {
+				if installation.Distribution == d {
+					preferred = append(preferred, installation)
+				}
+			}
+			if len(preferred) > 0 {
+				found = preferred
+			}
 		}
 	}
-	return Version{}, fmt.Errorf("not found installed python: %s", version.String())
-}
 
-func UninstallPython(config Config, version Version) error {
-	installedVersion, err := getInstalledPythonByMinor(config, version)
-	if !Confirm(fmt.Sprintf("uninstall python %s? [Y/n]", installedVersion.String())) {
-		return nil
+	switch len(found) {
+	case 0:
+		return Installation{}, &NotFoundError{fmt.Sprintf("not found installed python: %s", version.String())}
+	case 1:
+		return found[0], nil
+	}
+	var names []string
+	for _, installation := range found {
+		names = append(names, fmt.Sprintf("%s (%s, %s)", installation.Version.String(), installation.Arch, installation.Scope))
 	}
+	return Installation{}, &UsageError{fmt.Errorf(
+		"python %s is installed side by side: %s. select one by --arch or --scope",
+		version.String(), strings.Join(names, ", "),
+	)}
+}
 
-	fmt.Printf("uninstalling python %s\n", installedVersion.String())
+func UninstallPython(ctx context.Context, config Config, provider Provider, version Version, filter InstallationFilter) error {
+	if err := filter.validate(); err != nil {
+		return err
+	}
+	installation, err := findManagedInstallation(config, version, filter)
 	if err != nil {
 		return err
 	}
-	return doUninstall(config, installedVersion)
+	var installations []Installation
+	for _, i := range installedInstallations {
+		installations = append(installations, i)
+	}
+	label := installation.Version.String() + sideBySideLabel(installations, installation)
+	if !Confirm(ctx, fmt.Sprintf("uninstall python %s? [Y/n]", label)) {
+		return nil
+	}
+
+	fmt.Fprintf(MessageOut(), "uninstalling python %s\n", label)
+	return doUninstall(ctx, config, provider, installation)
 }
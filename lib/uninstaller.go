@@ -0,0 +1,160 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	uninstallKeyPath      = `Software\Microsoft\Windows\CurrentVersion\Uninstall`
+	uninstallKeyPath32bit = `Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall`
+)
+
+// UninstallEntry is an uninstall entry registered by the installer. (Add/Remove Programs)
+type UninstallEntry struct {
+	DisplayName     string
+	DisplayVersion  string
+	UninstallString string
+	// BundleCachePath is the installer cached by windows. (set by WiX burn bundle, like python installer)
+	BundleCachePath string
+	// Registry is "HKLM" or "HKCU".
+	Registry string
+}
+
+// uninstallerSource provides installers to uninstall python.
+// sources are tried in order: cached installer, registered uninstall entry, and download.
+type uninstallerSource interface {
+	// CachedInstaller returns the path of the cached installer of version, if exists.
+	CachedInstaller(version Version, arch string) (string, bool)
+	// UninstallEntries returns the uninstall entries registered in the system.
+	UninstallEntries() ([]UninstallEntry, error)
+	// InstallerExists reports whether the installer of an uninstall entry exists. (windows may remove its cache)
+	InstallerExists(path string) bool
+	// DownloadInstaller downloads the installer of version for arch, and returns the path.
+	DownloadInstaller(ctx context.Context, version Version, arch string) (string, error)
+}
+
+// registryUninstallEntries returns uninstall entries of python in HKLM (64bit and 32bit view) and HKCU.
+func registryUninstallEntries(reader RegistryReader) ([]UninstallEntry, error) {
+	var entries []UninstallEntry
+	var lastErr error
+	for _, key := range []struct {
+		root string
+		path string
+	}{
+		{"HKLM", uninstallKeyPath},
+		{"HKLM", uninstallKeyPath32bit},
+		{"HKCU", uninstallKeyPath},
+	} {
+		names, err := reader.SubKeyNames(key.root, key.path)
+		if err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				lastErr = err
+			}
+			continue
+		}
+		for _, name := range names {
+			entryPath := key.path + `\` + name
+			entry := UninstallEntry{Registry: key.root}
+			entry.DisplayName, _ = reader.StringValue(key.root, entryPath, "DisplayName")
+			// only python is needed.
+			if !strings.HasPrefix(entry.DisplayName, "Python ") {
+				continue
+			}
+			entry.DisplayVersion, _ = reader.StringValue(key.root, entryPath, "DisplayVersion")
+			entry.UninstallString, _ = reader.StringValue(key.root, entryPath, "UninstallString")
+			entry.BundleCachePath, _ = reader.StringValue(key.root, entryPath, "BundleCachePath")
+			entries = append(entries, entry)
+		}
+	}
+	return entries, lastErr
+}
+
+// bundleDisplayName returns DisplayName of the uninstall entry registered by python installer.
+// e.g. "Python 3.12.7 (64-bit)", "Python 3.13.0rc1 (32-bit)", "Python 3.12.7 (ARM64)"
+func bundleDisplayName(version Version, arch string) string {
+	label := "64-bit"
+	switch arch {
+	case "386":
+		label = "32-bit"
+	case "arm64":
+		label = "ARM64"
+	}
+	return fmt.Sprintf("Python %s (%s)", version.getFullString(), label)
+}
+
+// installerOfEntry returns the installer of the entry. UninstallString is like `"C:\...\python-3.12.7-amd64.exe"  /uninstall`.
+func installerOfEntry(entry UninstallEntry) string {
+	if entry.BundleCachePath != "" {
+		return entry.BundleCachePath
+	}
+	command := strings.TrimSpace(entry.UninstallString)
+	if strings.HasPrefix(command, `"`) {
+		if path, _, ok := strings.Cut(command[1:], `"`); ok {
+			return path
+		}
+		return ""
+	}
+	// MsiExec.exe is not the installer bundle.
+	if path, _, _ := strings.Cut(command, " "); strings.HasSuffix(strings.ToLower(path), ".exe") && !strings.EqualFold(path, "MsiExec.exe") {
+		return path
+	}
+	return ""
+}
+
+// findUninstaller returns the installer to uninstall version, without downloading if possible.
+func findUninstaller(ctx context.Context, source uninstallerSource, version Version, arch string) (string, error) {
+	if path, ok := source.CachedInstaller(version, arch); ok {
+		return path, nil
+	}
+
+	entries, err := source.UninstallEntries()
+	if err != nil && WithVerbose > 0 {
+		fmt.Fprintf(MessageOut(), "can not read uninstall entries: %s\n", err.Error())
+	}
+	name := bundleDisplayName(version, arch)
+	for _, entry := range entries {
+		if entry.DisplayName != name {
+			continue
+		}
+		if path := installerOfEntry(entry); path != "" && source.InstallerExists(path) {
+			if WithVerbose > 0 {
+				fmt.Fprintf(MessageOut(), "use the registered installer (%s): %s\n", entry.Registry, path)
+			}
+			return path, nil
+		}
+	}
+
+	path, err := source.DownloadInstaller(ctx, version, arch)
+	if err != nil {
+		return "", fmt.Errorf("can not find the installer of python %s to uninstall: %w", version.String(), err)
+	}
+	return path, nil
+}
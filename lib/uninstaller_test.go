@@ -0,0 +1,141 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fakeUninstallerSource is uninstallerSource which records the calls.
+type fakeUninstallerSource struct {
+	cached     map[string]string // "version arch" -> path
+	entries    []UninstallEntry
+	files      map[string]bool
+	downloaded []string
+}
+
+func (s *fakeUninstallerSource) CachedInstaller(version Version, arch string) (string, bool) {
+	path, ok := s.cached[version.String()+" "+arch]
+	return path, ok
+}
+
+func (s *fakeUninstallerSource) UninstallEntries() ([]UninstallEntry, error) {
+	return s.entries, nil
+}
+
+func (s *fakeUninstallerSource) InstallerExists(path string) bool {
+	return s.files[path]
+}
+
+func (s *fakeUninstallerSource) DownloadInstaller(ctx context.Context, version Version, arch string) (string, error) {
+	s.downloaded = append(s.downloaded, version.String()+" "+arch)
+	if version.Minor == 99 {
+		return "", &StatusError{404}
+	}
+	return `C:\cache\download-` + version.String() + "-" + arch + ".exe", nil
+}
+
+func TestFindUninstaller(t *testing.T) {
+	entries := []UninstallEntry{
+		{DisplayName: "Python 3.12.7 (64-bit)", BundleCachePath: `C:\pkg\amd64.exe`, Registry: "HKLM"},
+		{DisplayName: "Python 3.12.7 (32-bit)", UninstallString: `"C:\pkg\386.exe"  /uninstall`, Registry: "HKLM"},
+		{DisplayName: "Python 3.12.7 (ARM64)", UninstallString: `C:\pkg\arm64.exe /uninstall`, Registry: "HKCU"},
+		{DisplayName: "Python 3.11.9 (64-bit)", BundleCachePath: `C:\pkg\removed.exe`, Registry: "HKLM"},
+		{DisplayName: "Python 3.10.11 Core Interpreter (64-bit)", UninstallString: `MsiExec.exe /I{X}`, Registry: "HKLM"},
+	}
+	files := map[string]bool{`C:\pkg\amd64.exe`: true, `C:\pkg\386.exe`: true, `C:\pkg\arm64.exe`: true}
+
+	tests := []struct {
+		name         string
+		version      string
+		arch         string
+		cached       map[string]string
+		want         string
+		wantDownload bool
+	}{
+		{"cached first", "3.12.7", "amd64", map[string]string{"3.12.7 amd64": `C:\cache\python-3.12.7-amd64.exe`}, `C:\cache\python-3.12.7-amd64.exe`, false},
+		{"registered 64-bit", "3.12.7", "amd64", nil, `C:\pkg\amd64.exe`, false},
+		{"registered 32-bit", "3.12.7", "386", nil, `C:\pkg\386.exe`, false},
+		{"registered arm64", "3.12.7", "arm64", nil, `C:\pkg\arm64.exe`, false},
+		{"cached of other arch is not used", "3.12.7", "386", map[string]string{"3.12.7 amd64": `C:\cache\python-3.12.7-amd64.exe`}, `C:\pkg\386.exe`, false},
+		{"registered installer is removed", "3.11.9", "amd64", nil, `C:\cache\download-3.11.9-amd64.exe`, true},
+		{"not registered", "3.13.0", "386", nil, `C:\cache\download-3.13.0-386.exe`, true},
+		{"msi is not the installer", "3.10.11", "amd64", nil, `C:\cache\download-3.10.11-amd64.exe`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &fakeUninstallerSource{cached: tt.cached, entries: entries, files: files}
+			version, err := NewVersion(tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := findUninstaller(context.Background(), source, version, tt.arch)
+			if err != nil {
+				t.Fatalf("findUninstaller returns error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("findUninstaller = %q, want %q", got, tt.want)
+			}
+			if (len(source.downloaded) > 0) != tt.wantDownload {
+				t.Errorf("downloaded = %v, want download: %v", source.downloaded, tt.wantDownload)
+			}
+		})
+	}
+}
+
+func TestFindUninstallerDownloadFailed(t *testing.T) {
+	source := &fakeUninstallerSource{}
+	version, _ := NewVersion("3.99.0")
+	_, err := findUninstaller(context.Background(), source, version, "amd64")
+	var sErr *StatusError
+	if !errors.As(err, &sErr) {
+		t.Errorf("findUninstaller returns %v, want StatusError", err)
+	}
+}
+
+func TestBundleDisplayName(t *testing.T) {
+	tests := []struct {
+		version string
+		arch    string
+		want    string
+	}{
+		{"3.12.7", "amd64", "Python 3.12.7 (64-bit)"},
+		{"3.12.7", "386", "Python 3.12.7 (32-bit)"},
+		{"3.12.7", "arm64", "Python 3.12.7 (ARM64)"},
+		{"3.13.0rc1", "386", "Python 3.13.0rc1 (32-bit)"},
+		{"3.13.1t", "amd64", "Python 3.13.1 (64-bit)"},
+	}
+	for _, tt := range tests {
+		version, err := NewVersion(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bundleDisplayName(version, tt.arch); got != tt.want {
+			t.Errorf("bundleDisplayName(%s, %s) = %q, want %q", tt.version, tt.arch, got, tt.want)
+		}
+	}
+}
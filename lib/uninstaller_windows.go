@@ -0,0 +1,68 @@
+//go:build windows
+
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// systemUninstallerSource is uninstallerSource of this machine.
+type systemUninstallerSource struct {
+	config   Config
+	provider Provider
+}
+
+// CachedInstaller returns the cached installer only if it is verified and not modified.
+func (s systemUninstallerSource) CachedInstaller(version Version, arch string) (string, bool) {
+	artifact, err := nativeArtifact(s.config, version, arch)
+	if err != nil {
+		return "", false
+	}
+	path := filepath.Join(installerCacheDir, artifact.FileName)
+	expected, err := os.ReadFile(path + sha256Suffix)
+	if err != nil {
+		return "", false
+	}
+	actual, err := fileSha256(path)
+	return path, err == nil && strings.EqualFold(strings.TrimSpace(string(expected)), actual)
+}
+
+func (s systemUninstallerSource) UninstallEntries() ([]UninstallEntry, error) {
+	return registryUninstallEntries(windowsRegistry{})
+}
+
+func (s systemUninstallerSource) InstallerExists(path string) bool {
+	return isFile(path)
+}
+
+func (s systemUninstallerSource) DownloadInstaller(ctx context.Context, version Version, arch string) (string, error) {
+	_, path, err := downloadArtifact(ctx, s.config, s.provider, version, arch)
+	return path, err
+}
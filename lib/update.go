@@ -25,58 +25,107 @@ SOFTWARE.
 package lib
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"sort"
 )
 
-func UpdateLatest(config Config, version Version) error {
-	if err := detectUpdatablePythonVersions(config); err != nil {
+// UpdateLatest updates installations of the minor of version. installations side by side (see installKey) are all updated,
+// unless filter selects them.
+func UpdateLatest(ctx context.Context, config Config, provider Provider, version Version, filter InstallationFilter) error {
+	if err := filter.validate(); err != nil {
 		return err
 	}
-	minor := version.Minor
-	if latestVersion, ok := updatablePythonVersions[minor]; ok {
-		return doUpdate(config, latestVersion)
+	if err := detectUpdatablePythonVersions(ctx, config, provider); err != nil {
+		return err
 	}
-	return fmt.Errorf("this version is already latest version")
-}
 
-func UpdateAll(config Config) error {
-	if err := detectUpdatablePythonVersions(config); err != nil {
-		return err
+	var keys []installKey
+	for _, key := range sortedInstallKeys(updatablePythonVersions) {
+		if key.Minor == version.Minor && key.FreeThreaded == version.FreeThreaded && filter.match(key) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("this version is already latest version")
 	}
 
-	if len(updatablePythonVersions) == 0 {
-		fmt.Println("There is no updatable python.")
-		return nil
+	var errs []error
+	for _, key := range keys {
+		if len(keys) > 1 {
+			fmt.Fprintf(MessageOut(), "updating python %s (%s, %s)\n", installedPythonVersions[key].String(), key.Arch, key.Scope)
+		}
+		if err := doUpdate(ctx, config, provider, installedInstallations[key], updatablePythonVersions[key]); isCanceled(err) {
+			return err
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
 	}
+	pruneByRetention(ctx, config)
+	return nil
+}
 
-	minorVersions := make([]int, 0, len(updatablePythonVersions))
-	for k := range updatablePythonVersions {
-		minorVersions = append(minorVersions, k)
+// UpdateAll updates all updatable python. if dryRun, only the plan is shown.
+func UpdateAll(ctx context.Context, config Config, provider Provider, dryRun bool) error {
+	if err := detectUpdatablePythonVersions(ctx, config, provider); err != nil {
+		return err
 	}
-	sort.Ints(minorVersions)
 
-	fmt.Printf("update versions are:\n")
-	for _, minor := range minorVersions {
+	keys := sortedInstallKeys(updatablePythonVersions)
+	doc := UpdatePlanDocument{Header: newHeader("update-plan"), Updates: []UpdatePlanEntry{}}
+	for _, key := range keys {
 		var verStr string
-		if v, ok := installedPythonVersions[minor]; ok {
+		if v, ok := installedPythonVersions[key]; ok {
 			verStr = v.String()
 		}
-		fmt.Printf("%s -> %s\n", verStr, updatablePythonVersions[minor].Value.String())
+		target := key.variantOf(updatablePythonVersions[key].Value)
+		doc.Updates = append(doc.Updates, UpdatePlanEntry{
+			Minor:        target.getMinorString(),
+			FreeThreaded: key.FreeThreaded,
+			Arch:         key.Arch,
+			Scope:        key.Scope,
+			Installed:    verStr,
+			Target:       target.String(),
+			Status:       UpdatePlanned,
+		})
 	}
 
-	if !Confirm("Do you want to update all updatable python? [Y/n]") {
-		return nil
+	if len(keys) == 0 {
+		fmt.Fprintln(MessageOut(), "There is no updatable python.")
+		return WriteDocument(doc)
 	}
 
-	fmt.Println("start updating...")
-	for _, minor := range minorVersions {
-		ver := updatablePythonVersions[minor]
-		fmt.Printf("updating python %s\n", ver.Value.String())
-		if err := doUpdate(config, ver); err != nil {
-			fmt.Printf("an error occurred while updating python %s: %s\n", ver.Value.String(), err.Error())
+	var installations []Installation
+	for _, key := range keys {
+		installations = append(installations, installedInstallations[key])
+	}
+	fmt.Fprintf(MessageOut(), "update versions are:\n")
+	for i, entry := range doc.Updates {
+		fmt.Fprintf(MessageOut(), "%s -> %s%s\n", entry.Installed, entry.Target, sideBySideLabel(installations, installations[i]))
+	}
+
+	if dryRun || !Confirm(ctx, "Do you want to update all updatable python? [Y/n]") {
+		return WriteDocument(doc)
+	}
+
+	fmt.Fprintln(MessageOut(), "start updating...")
+	for i, key := range keys {
+		ver := key.variantOf(updatablePythonVersions[key].Value)
+		fmt.Fprintf(MessageOut(), "updating python %s%s\n", ver.String(), sideBySideLabel(installations, installations[i]))
+		if err := doUpdate(ctx, config, provider, installedInstallations[key], updatablePythonVersions[key]); isCanceled(err) {
+			return err
+		} else if err != nil {
+			fmt.Fprintf(MessageOut(), "an error occurred while updating python %s: %s\n", ver.String(), err.Error())
+			doc.Updates[i].Status = UpdateFailed
+			doc.Updates[i].Error = err.Error()
+		} else {
+			doc.Updates[i].Status = UpdateDone
 		}
 	}
+	pruneByRetention(ctx, config)
 
-	return nil
+	return WriteDocument(doc)
 }
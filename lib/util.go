@@ -26,6 +26,7 @@ package lib
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
 	"os"
@@ -36,23 +37,37 @@ var (
 	SkipConfirm bool
 )
 
-func Confirm(str string) bool {
+// Confirm asks yes or no. canceling ctx (e.g. Ctrl-C) is treated as no.
+func Confirm(ctx context.Context, str string) bool {
 	if SkipConfirm {
 		return true
 	}
 
-	fmt.Print(str)
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		switch strings.ToLower(scanner.Text()) {
-		case "y", "":
-			return true
-		case "n":
-			return false
+	answer := make(chan bool, 1)
+	go func() {
+		fmt.Fprint(MessageOut(), str)
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			switch strings.ToLower(scanner.Text()) {
+			case "y", "":
+				answer <- true
+				return
+			case "n":
+				answer <- false
+				return
+			}
+			fmt.Fprint(MessageOut(), str)
 		}
-		fmt.Print(str)
+		answer <- false
+	}()
+
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(MessageOut())
+		return false
+	case a := <-answer:
+		return a
 	}
-	return false
 }
 
 func YesOrNo(f bool) string {
@@ -62,6 +77,11 @@ func YesOrNo(f bool) string {
 	return "not"
 }
 
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
 func deferErrCheck(fun func() error) {
 	if err := fun(); err != nil {
 		cobra.CheckErr(err)
@@ -0,0 +1,115 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/hawk-tomy/pim/lib/list"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useTempDirs redirects the cache and data directories of pim into a temporary directory.
+func useTempDirs(t *testing.T) string {
+	t.Helper()
+	dirs := []*string{&cacheDir, &versionCacheFile, &installerCacheDir, &quarantineDir, &dataDir, &standaloneDir, &shimDir}
+	saved := make([]string, len(dirs))
+	for i, dir := range dirs {
+		saved[i] = *dir
+	}
+	t.Cleanup(func() {
+		for i, dir := range dirs {
+			*dir = saved[i]
+		}
+	})
+
+	root := t.TempDir()
+	cacheDir = filepath.Join(root, "cache")
+	versionCacheFile = filepath.Join(cacheDir, "cache.json")
+	installerCacheDir = filepath.Join(cacheDir, "installer")
+	quarantineDir = filepath.Join(installerCacheDir, "quarantine")
+	dataDir = filepath.Join(root, "data")
+	standaloneDir = filepath.Join(dataDir, "standalone")
+	shimDir = filepath.Join(dataDir, "bin")
+	if err := os.MkdirAll(installerCacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// useHttpClient replaces the shared client with c.
+func useHttpClient(t *testing.T, c *httpClient) {
+	t.Helper()
+	sharedClientOnce.Do(func() {})
+	saved, savedErr := sharedClient, sharedClientErr
+	sharedClient, sharedClientErr = c, nil
+	t.Cleanup(func() { sharedClient, sharedClientErr = saved, savedErr })
+}
+
+// redirectTransport sends all requests to target, so fixed urls (e.g. GitHub API) are served by test server.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (r redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = r.target.Scheme, r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// useTestServer starts test server with handler, and the shared client sends all requests to it without retry.
+func useTestServer(t *testing.T, handler http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	useHttpClient(t, &httpClient{&http.Client{Transport: redirectTransport{target}}, 5 * time.Second, 0})
+	return server
+}
+
+func sha256Hex(b []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// resetVersions forgets versions fetched by other tests.
+func resetVersions(t *testing.T) {
+	t.Helper()
+	allVersions = nil
+	fetchedVersions = make(map[int]*list.List[Version])
+	failedMinimumVersions = make(map[int]Version)
+	githubPages = make(map[int]GitHubPageCache)
+	fetchedLatestVersions = false
+	fetchedProvider = ""
+	fetchedAt = time.Time{}
+}
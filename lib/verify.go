@@ -0,0 +1,354 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// downloaded files are verified before use, by Artifact.Verification given by the provider.
+//   - SHA-256: python.org artifacts are checked against the hash published by python.org API.
+//     python-build-standalone archives are checked against SHA256SUMS (or "<file>.sha256") of the release.
+//     artifacts of other providers are checked against Artifact.Sha256.
+//   - Sigstore: python.org artifacts are checked against "<file>.sigstore" bundle by cosign, if both are available.
+//
+// verified hash is saved as "<file>.sha256" next to the cached file, and cached file is re-verified with it before use.
+// file imported from a bundle is not verified by the bundle itself. (see bundleProvenance)
+// file which fails verification is moved into quarantine directory.
+const (
+	DefaultPythonApiBaseUrl = "https://www.python.org/api/v2/downloads"
+	sha256Suffix            = ".sha256"
+	sigstoreSuffix          = ".sigstore"
+	standaloneSumsFileName  = "SHA256SUMS"
+)
+
+// sigstoreIdentity is the signer of python.org artifacts. see https://www.python.org/downloads/metadata/sigstore/
+type sigstoreIdentity struct {
+	Identity string
+	Issuer   string
+}
+
+var (
+	quarantineDir string
+	// release managers of each minor version.
+	sigstoreIdentities = map[int]sigstoreIdentity{
+		7:  {"nad@python.org", "https://github.com/login/oauth"},
+		8:  {"lukasz@langa.pl", "https://github.com/login/oauth"},
+		9:  {"lukasz@langa.pl", "https://github.com/login/oauth"},
+		10: {"pablogsal@python.org", "https://accounts.google.com"},
+		11: {"pablogsal@python.org", "https://accounts.google.com"},
+		12: {"thomas@python.org", "https://accounts.google.com"},
+		13: {"thomas@python.org", "https://accounts.google.com"},
+		14: {"hugo@python.org", "https://github.com/login/oauth"},
+		15: {"hugo@python.org", "https://github.com/login/oauth"},
+	}
+)
+
+func init() {
+	quarantineDir = filepath.Join(installerCacheDir, "quarantine")
+}
+
+// VerificationError is returned when downloaded file does not match published hash or signature.
+type VerificationError struct {
+	FileName string
+	Reason   string
+	// Quarantine is the path the file is moved to.
+	Quarantine string
+}
+
+func (e *VerificationError) Error() string {
+	return fmt.Sprintf("verification failed: %s: %s (moved to %s)", e.FileName, e.Reason, e.Quarantine)
+}
+
+func pythonApiBaseUrl(config Config) string {
+	if config.PythonApiBaseUrl != "" {
+		return strings.TrimSuffix(config.PythonApiBaseUrl, "/")
+	}
+	return DefaultPythonApiBaseUrl
+}
+
+func fileSha256(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer deferErrCheck(f.Close)
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func getText(ctx context.Context, config Config, url string) (string, error) {
+	resp, err := httpGet(ctx, config, url)
+	if err != nil {
+		return "", err
+	}
+	defer deferErrCheck(resp.Body.Close)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &StatusError{resp.StatusCode}
+	}
+	b, err := io.ReadAll(resp.Body)
+	return string(b), err
+}
+
+func getJson(ctx context.Context, config Config, url string, v any) error {
+	text, err := getText(ctx, config, url)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(text), v)
+}
+
+// pythonOrgReleaseFile is a file of python.org API "release_file".
+type pythonOrgReleaseFile struct {
+	Url                string `json:"url"`
+	Sha256Sum          string `json:"sha256_sum"`
+	SigstoreBundleFile string `json:"sigstore_bundle_file"`
+}
+
+// findPythonOrgReleaseFile returns the file of artifact from python.org API.
+func findPythonOrgReleaseFile(ctx context.Context, config Config, version Version, artifact Artifact) (pythonOrgReleaseFile, error) {
+	var releases []struct {
+		ResourceUri string `json:"resource_uri"`
+	}
+	name := url.QueryEscape("Python " + version.getFullString())
+	if err := getJson(ctx, config, fmt.Sprintf("%s/release/?name=%s", pythonApiBaseUrl(config), name), &releases); err != nil {
+		return pythonOrgReleaseFile{}, err
+	}
+	if len(releases) == 0 {
+		return pythonOrgReleaseFile{}, fmt.Errorf("release is not found in python.org API: %s", version.getFullString())
+	}
+	// resource_uri is like "https://www.python.org/api/v2/downloads/release/927/"
+	id := path.Base(strings.TrimSuffix(releases[0].ResourceUri, "/"))
+
+	var files []pythonOrgReleaseFile
+	if err := getJson(ctx, config, fmt.Sprintf("%s/release_file/?release=%s", pythonApiBaseUrl(config), id), &files); err != nil {
+		return pythonOrgReleaseFile{}, err
+	}
+	for _, file := range files {
+		if path.Base(file.Url) == artifact.FileName {
+			return file, nil
+		}
+	}
+	return pythonOrgReleaseFile{}, fmt.Errorf("file is not found in python.org API: %s", artifact.FileName)
+}
+
+// standaloneSha256 returns the hash of python-build-standalone archive published in the release.
+func standaloneSha256(ctx context.Context, config Config, artifact Artifact) (string, error) {
+	releaseUrl := strings.TrimSuffix(artifact.Url, "/"+artifact.FileName)
+	if sums, err := getText(ctx, config, releaseUrl+"/"+standaloneSumsFileName); err == nil {
+		scanner := bufio.NewScanner(strings.NewReader(sums))
+		for scanner.Scan() {
+			// "<hash>  <file name>"
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && fields[1] == artifact.FileName {
+				return fields[0], nil
+			}
+		}
+	}
+	text, err := getText(ctx, config, artifact.Url+sha256Suffix)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("invalid hash file: %s", artifact.Url+sha256Suffix)
+	}
+	return fields[0], nil
+}
+
+// quarantine moves the file (and its sidecar files) into quarantineDir, and returns VerificationError.
+func quarantine(filePath string, reason string) error {
+	vErr := &VerificationError{FileName: filepath.Base(filePath), Reason: reason}
+	if err := os.MkdirAll(quarantineDir, 0755); err != nil {
+		return err
+	}
+	vErr.Quarantine = filepath.Join(quarantineDir, fmt.Sprintf("%s.%d", vErr.FileName, time.Now().Unix()))
+	if err := os.Rename(filePath, vErr.Quarantine); err != nil {
+		return err
+	}
+	for _, suffix := range []string{sha256Suffix, sigstoreSuffix, bundleSuffix} {
+		if err := os.Remove(filePath + suffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return vErr
+}
+
+// verifySigstore verifies the file with sigstore bundle by cosign.
+// it returns false if bundle or cosign is not available.
+func verifySigstore(ctx context.Context, config Config, version Version, filePath string, bundleUrl string) (bool, error) {
+	identity, ok := sigstoreIdentities[version.Minor]
+	if !ok {
+		return false, nil
+	}
+	cosign, err := exec.LookPath("cosign")
+	if err != nil {
+		return false, nil
+	}
+
+	bundlePath := filePath + sigstoreSuffix
+	if !isFile(bundlePath) {
+		if bundleUrl == "" {
+			return false, nil
+		}
+		bundle, err := getText(ctx, config, bundleUrl)
+		var sErr *StatusError
+		if errors.As(err, &sErr) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(bundlePath, []byte(bundle), 0644); err != nil {
+			return false, err
+		}
+	}
+
+	args := []string{
+		"verify-blob",
+		"--bundle", bundlePath,
+		"--new-bundle-format",
+		"--certificate-identity", identity.Identity,
+		"--certificate-oidc-issuer", identity.Issuer,
+	}
+	if config.Offline {
+		// use the trusted root cached by cosign.
+		args = append(args, "--offline")
+	}
+	cmd := exec.CommandContext(ctx, cosign, append(args, filePath)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return true, quarantine(filePath, fmt.Sprintf("sigstore: %s", strings.TrimSpace(string(out))))
+	}
+	return true, nil
+}
+
+// verifyArtifact verifies downloaded (or cached) artifact at filePath.
+func verifyArtifact(ctx context.Context, config Config, version Version, artifact Artifact, filePath string) error {
+	actual, err := fileSha256(filePath)
+	if err != nil {
+		return err
+	}
+
+	// already verified. check the file is not modified.
+	if b, err := os.ReadFile(filePath + sha256Suffix); err == nil {
+		if expected := strings.TrimSpace(string(b)); !strings.EqualFold(expected, actual) {
+			return quarantine(filePath, fmt.Sprintf("sha256 mismatch with verified hash. expected: %s, actual: %s", expected, actual))
+		}
+		return nil
+	}
+
+	provenance, imported := readBundleProvenance(filePath)
+	if imported && !strings.EqualFold(provenance.Sha256, actual) {
+		return quarantine(filePath, fmt.Sprintf("sha256 mismatch with bundle manifest. expected: %s, actual: %s", provenance.Sha256, actual))
+	}
+
+	var expected, bundleUrl string
+	switch artifact.Verification {
+	case VerifySha256:
+		expected = artifact.Sha256
+	case VerifyStandaloneSums:
+		expected, err = standaloneSha256(ctx, config, artifact)
+	case VerifyPythonOrg:
+		var file pythonOrgReleaseFile
+		file, err = findPythonOrgReleaseFile(ctx, config, version, artifact)
+		expected, bundleUrl = file.Sha256Sum, file.SigstoreBundleFile
+		if bundleUrl == "" {
+			bundleUrl = artifact.Url + sigstoreSuffix
+		}
+	}
+	if err == nil && expected == "" {
+		err = fmt.Errorf("sha256 is not published for %s", artifact.FileName)
+	}
+	if err != nil && imported {
+		// published hash is not available (e.g. offline). the signature in the bundle is verified instead.
+		if err = verifyImportedInstaller(ctx, config, version, filePath, provenance); err == nil {
+			return nil
+		}
+	}
+	if err != nil {
+		if config.AllowUnverifiedDownloads {
+			fmt.Fprintf(MessageOut(), "warning: can not verify %s: %s\n", artifact.FileName, err.Error())
+			return nil
+		}
+		if imported {
+			return err
+		}
+		var oErr *OfflineError
+		if errors.As(err, &oErr) {
+			return &OfflineError{fmt.Sprintf("published hash of %s", artifact.FileName)}
+		}
+		return fmt.Errorf("can not get published hash of %s (set AllowUnverifiedDownloads to skip verification): %s", artifact.FileName, err.Error())
+	}
+	if !strings.EqualFold(expected, actual) {
+		return quarantine(filePath, fmt.Sprintf("sha256 mismatch. expected: %s, actual: %s", expected, actual))
+	}
+
+	if artifact.Verification == VerifyPythonOrg {
+		verified, err := verifySigstore(ctx, config, version, filePath, bundleUrl)
+		if err != nil {
+			return err
+		}
+		if !verified {
+			if config.RequireSigstore {
+				return fmt.Errorf("can not verify sigstore bundle of %s (cosign and the bundle are required)", artifact.FileName)
+			}
+			if WithVerbose > 0 {
+				fmt.Fprintf(MessageOut(), "sigstore verification is skipped: %s\n", artifact.FileName)
+			}
+		}
+	}
+
+	return markVerified(filePath, actual)
+}
+
+// markVerified writes the verified hash of filePath. provenance of bundle is not needed any more.
+func markVerified(filePath string, sha256 string) error {
+	if err := os.WriteFile(filePath+sha256Suffix, []byte(strings.ToLower(sha256)+"\n"), 0644); err != nil {
+		return err
+	}
+	if err := os.Remove(filePath + bundleSuffix); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -31,8 +31,17 @@ import (
 	"strings"
 )
 
+// regex is PEP 440 version (https://peps.python.org/pep-0440/) with free-threaded suffix ("t").
+// alternative spellings of PEP 440 (e.g. "alpha", "-1", "rev") are accepted and normalized.
 const (
-	regex = `^v?(?P<major>\d)+(?:\.(?P<minor>\d+))?(?:\.(?P<micro>\d+))?(?:(?P<pre>a|b|rc)(?P<preN>\d+))?$`
+	regex = `(?i)^v?` +
+		`(?:(?P<epoch>\d+)!)?` +
+		`(?P<major>\d+)(?:\.(?P<minor>\d+))?(?:\.(?P<micro>\d+))?` +
+		`(?:[-_.]?(?P<pre>a|b|c|rc|alpha|beta|pre|preview)[-_.]?(?P<preN>\d+)?)?` +
+		`(?:-(?P<postImplicitN>\d+)|[-_.]?(?P<post>post|rev|r)[-_.]?(?P<postN>\d+)?)?` +
+		`(?:[-_.]?(?P<dev>dev)[-_.]?(?P<devN>\d+)?)?` +
+		`(?P<freeThreaded>t)?` +
+		`(?:\+(?P<local>[a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`
 )
 
 var (
@@ -44,100 +53,206 @@ func init() {
 }
 
 type Version struct {
-	Major  int
-	Minor  int
-	Micro  int
-	Pre    int
-	PreNum int
+	Epoch        int
+	Major        int
+	Minor        int
+	Micro        int
+	Pre          int // 0: final, -1: rc, -2: b, -3: a
+	PreNum       int
+	Post         bool
+	PostNum      int
+	Dev          bool
+	DevNum       int
+	Local        string
+	FreeThreaded bool
 }
 
 func NewVersion(versionString string) (Version, error) {
 	var v Version
-	var n int
 	var err error
 
-	matches := versionRegex.FindStringSubmatch(versionString)
+	matches := versionRegex.FindStringSubmatch(strings.TrimSpace(versionString))
 	if matches == nil {
 		return Version{}, fmt.Errorf("invalid version string (not match): %s", versionString)
 	}
-
-	l := len(matches)
-
-	n = 0
-	if l > 1 && matches[1] != "" {
-		if n, err = strconv.Atoi(matches[1]); err != nil {
-			return Version{}, fmt.Errorf("invalid version string (Major): %s", versionString)
-		}
+	group := func(name string) string {
+		return matches[versionRegex.SubexpIndex(name)]
 	}
-	v.Major = n
-
-	n = 0
-	if l > 2 && matches[2] != "" {
-		if n, err = strconv.Atoi(matches[2]); err != nil {
-			return Version{}, fmt.Errorf("invalid version string (Minor): %s", versionString)
+	number := func(name string) (int, error) {
+		s := group(name)
+		if s == "" {
+			return 0, nil
 		}
-	}
-	v.Minor = n
-
-	n = 0
-	if l > 3 && matches[3] != "" {
-		n, err = strconv.Atoi(matches[3])
+		n, err := strconv.Atoi(s)
 		if err != nil {
-			return Version{}, fmt.Errorf("invalid version string (Micro): %s", versionString)
+			return 0, fmt.Errorf("invalid version string (%s): %s", name, versionString)
 		}
+		return n, nil
 	}
-	v.Micro = n
 
-	if l > 4 && matches[4] != "" {
-		if matches[4] == "rc" {
+	if v.Epoch, err = number("epoch"); err != nil {
+		return Version{}, err
+	}
+	if v.Major, err = number("major"); err != nil {
+		return Version{}, err
+	}
+	if v.Minor, err = number("minor"); err != nil {
+		return Version{}, err
+	}
+	if v.Micro, err = number("micro"); err != nil {
+		return Version{}, err
+	}
+
+	if pre := strings.ToLower(group("pre")); pre != "" {
+		switch pre {
+		case "rc", "c", "pre", "preview":
 			v.Pre = -1
-		} else if matches[4] == "b" {
+		case "b", "beta":
 			v.Pre = -2
-		} else if matches[4] == "a" {
+		case "a", "alpha":
 			v.Pre = -3
-		} else {
+		default:
 			panic("UNREACHABLE: invalid version string (Pre)")
 		}
+		if v.PreNum, err = number("preN"); err != nil {
+			return Version{}, err
+		}
+	}
+
+	if group("postImplicitN") != "" {
+		v.Post = true
+		if v.PostNum, err = number("postImplicitN"); err != nil {
+			return Version{}, err
+		}
+	} else if group("post") != "" {
+		v.Post = true
+		if v.PostNum, err = number("postN"); err != nil {
+			return Version{}, err
+		}
+	}
 
-		if n, err = strconv.Atoi(matches[5]); err != nil {
-			return Version{}, fmt.Errorf("invalid version string (Pre): %s", versionString)
+	if group("dev") != "" {
+		v.Dev = true
+		if v.DevNum, err = number("devN"); err != nil {
+			return Version{}, err
 		}
-		v.PreNum = n
-	} else {
-		v.Pre = 0
-		v.PreNum = 0
 	}
+
+	v.FreeThreaded = group("freeThreaded") != ""
+	v.Local = strings.ToLower(strings.NewReplacer("-", ".", "_", ".").Replace(group("local")))
+
 	return v, nil
 }
 
-func (v Version) Compare(o Version) int {
-	if v.Major > o.Major {
+func compareInt(a, b int) int {
+	if a > b {
 		return 1
-	} else if v.Major < o.Major {
+	} else if a < b {
 		return -1
 	}
+	return 0
+}
 
-	if v.Minor > o.Minor {
-		return 1
-	} else if v.Minor < o.Minor {
+// compareLocal compares local version labels. see PEP 440.
+//   - version without local label is older than with it.
+//   - numeric segments are compared numerically, and newer than alphanumeric segments.
+//   - alphanumeric segments are compared lexicographically.
+//   - if all segments are same, longer one is newer.
+func compareLocal(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
 		return -1
 	}
-
-	if v.Micro > o.Micro {
+	if b == "" {
 		return 1
-	} else if v.Micro < o.Micro {
-		return -1
 	}
 
-	if v.Pre > o.Pre {
-		return 1
-	} else if v.Pre < o.Pre {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInt(an, bn); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return 1
+		case bErr == nil:
+			return -1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInt(len(as), len(bs))
+}
+
+// preKey returns order of pre-release. "X.Y.Z.devN" (without pre and post) is older than any pre-release.
+func (v Version) preKey() int {
+	if v.Pre == 0 && !v.Post && v.Dev {
+		return -4
+	}
+	return v.Pre
+}
+
+// Compare returns 1 if v > o, -1 if v < o, and 0 if v == o, in PEP 440 order.
+// free-threaded build is newer than regular one, because both are same in PEP 440.
+func (v Version) Compare(o Version) int {
+	if c := compareInt(v.Epoch, o.Epoch); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Major, o.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Minor, o.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(v.Micro, o.Micro); c != 0 {
+		return c
+	}
+
+	if c := compareInt(v.preKey(), o.preKey()); c != 0 {
+		return c
+	}
+	if c := compareInt(v.PreNum, o.PreNum); c != 0 {
+		return c
+	}
+
+	// no post-release is older than any post-release.
+	if v.Post != o.Post {
+		if v.Post {
+			return 1
+		}
 		return -1
 	}
+	if c := compareInt(v.PostNum, o.PostNum); c != 0 {
+		return c
+	}
 
-	if v.PreNum > o.PreNum {
+	// no dev-release is newer than any dev-release.
+	if v.Dev != o.Dev {
+		if v.Dev {
+			return -1
+		}
 		return 1
-	} else if v.PreNum < o.PreNum {
+	}
+	if c := compareInt(v.DevNum, o.DevNum); c != 0 {
+		return c
+	}
+
+	if c := compareLocal(v.Local, o.Local); c != 0 {
+		return c
+	}
+
+	if v.FreeThreaded != o.FreeThreaded {
+		if v.FreeThreaded {
+			return 1
+		}
 		return -1
 	}
 
@@ -169,6 +284,11 @@ func (v Version) GreaterThanOrEqual(o Version) bool {
 	return v.Compare(o) >= 0
 }
 
+// IsPreRelease returns true if v is pre-release or dev-release.
+func (v Version) IsPreRelease() bool {
+	return v.Pre != 0 || v.Dev
+}
+
 func (v Version) getPreString() string {
 	switch v.Pre {
 	case -1:
@@ -182,25 +302,59 @@ func (v Version) getPreString() string {
 	}
 }
 
+func (v Version) getPostString() string {
+	if !v.Post {
+		return ""
+	}
+	return ".post" + strconv.Itoa(v.PostNum)
+}
+
+func (v Version) getDevString() string {
+	if !v.Dev {
+		return ""
+	}
+	return ".dev" + strconv.Itoa(v.DevNum)
+}
+
 func (v Version) getStringWithoutPre() string {
 	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Micro)
 }
 
+// getFullString returns the version used in file names of python.org. (without epoch, free-threaded and local)
 func (v Version) getFullString() string {
-	return v.getStringWithoutPre() + v.getPreString()
+	return v.getStringWithoutPre() + v.getPreString() + v.getPostString() + v.getDevString()
+}
+
+// getMinorString returns "Major.Minor", with "t" if free-threaded.
+func (v Version) getMinorString() string {
+	s := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	if v.FreeThreaded {
+		s += "t"
+	}
+	return s
 }
 
 func (v Version) String() string {
-	return v.getFullString()
+	s := v.getFullString()
+	if v.Epoch != 0 {
+		s = strconv.Itoa(v.Epoch) + "!" + s
+	}
+	if v.FreeThreaded {
+		s += "t"
+	}
+	if v.Local != "" {
+		s += "+" + v.Local
+	}
+	return s
 }
 
 // Count return:
 // if ?.0.0 -> 1
 // if ?.?.0 -> 2
 // if ?.?.? -> 3
-// if ?.?.?[a,b,rc]? -> 4
+// if ?.?.?[a,b,rc,.post,.dev]? -> 4
 func (v Version) Count() int {
-	if v.Pre != 0 {
+	if v.Pre != 0 || v.Post || v.Dev {
 		return 4
 	}
 	if v.Micro != 0 {
@@ -232,7 +386,7 @@ func (v *Version) UnmarshalJSON(data []byte) error {
 }
 
 func (v Version) MarshalJSON() ([]byte, error) {
-	return []byte(fmt.Sprintf(`"%s"`, v.getFullString())), nil
+	return []byte(fmt.Sprintf(`"%s"`, v.String())), nil
 }
 
 func PrintVersions(vs []Version) {
@@ -247,4 +401,4 @@ func PrintVersionMap(vm map[int]Version) {
 	}
 }
 
-var _ fmt.Stringer = Version{0, 0, 0, 0, 0}
+var _ fmt.Stringer = Version{}
@@ -0,0 +1,170 @@
+/*
+MIT License
+
+# Copyright (c) 2023 - present hawk-tomy
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewVersion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		count int
+	}{
+		{"3.12.7", "3.12.7", 3},
+		{"v3.12.7", "3.12.7", 3},
+		{"V3.12", "3.12.0", 2},
+		{"3.13.0t", "3.13.0t", 2},
+		{"3.13.0rc1t", "3.13.0rc1t", 4},
+		{"3.13.0-alpha.2", "3.13.0a2", 4},
+		{"3.12.0c1", "3.12.0rc1", 4},
+		{"3.12.0-1", "3.12.0.post1", 4},
+		{"3.12.0.rev2", "3.12.0.post2", 4},
+		{"3.12.0.dev", "3.12.0.dev0", 4},
+		{"1!3.12.0", "1!3.12.0", 2},
+		{"3.12.0+Ubuntu-1_2", "3.12.0+ubuntu.1.2", 2},
+		{" 3.11 ", "3.11.0", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := NewVersion(tt.input)
+			if err != nil {
+				t.Fatalf("NewVersion(%q) returns error: %v", tt.input, err)
+			}
+			if v.String() != tt.want {
+				t.Errorf("NewVersion(%q).String() = %q, want %q", tt.input, v.String(), tt.want)
+			}
+			if v.Count() != tt.count {
+				t.Errorf("NewVersion(%q).Count() = %d, want %d", tt.input, v.Count(), tt.count)
+			}
+		})
+	}
+}
+
+func TestNewVersionInvalid(t *testing.T) {
+	for _, input := range []string{"", "python", "3.12.x", "3..12", "3.12.0+", "3.12.0tt"} {
+		if v, err := NewVersion(input); err == nil {
+			t.Errorf("NewVersion(%q) = %s, want error", input, v.String())
+		}
+	}
+}
+
+func TestVersionCompare(t *testing.T) {
+	// each version is older than the next one.
+	ordered := []string{
+		"3.12.0.dev1",
+		"3.12.0a1.dev1",
+		"3.12.0a1",
+		"3.12.0a2",
+		"3.12.0b1",
+		"3.12.0rc1",
+		"3.12.0rc1.post1",
+		"3.12.0",
+		"3.12.0t",
+		"3.12.0+local",
+		"3.12.0+1",
+		"3.12.0.post1.dev1",
+		"3.12.0.post1",
+		"3.12.1",
+		"3.13.0a1",
+		"1!2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := NewVersion(ordered[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := NewVersion(ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := compareInt(i, j)
+			if got := a.Compare(b); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
+
+func TestVersionCompareLocal(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"3.12.0+1", "3.12.0+1", 0},
+		{"3.12.0+2", "3.12.0+10", -1},
+		{"3.12.0+abc", "3.12.0+1", -1},
+		{"3.12.0+1", "3.12.0+1.0", -1},
+		{"3.12.0+a-b", "3.12.0+a.b", 0},
+	}
+	for _, tt := range tests {
+		a, _ := NewVersion(tt.a)
+		b, _ := NewVersion(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVersionCacheJSON(t *testing.T) {
+	var versions []Version
+	for _, s := range []string{"3.12.7", "3.13.0rc1t", "1!3.12.0.post1.dev2+local.1", "3.14.0a1"} {
+		v, err := NewVersion(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		versions = append(versions, v)
+	}
+	cache := VersionCache{
+		SchemaVersion:         cacheSchemaVersion,
+		Provider:              "cpython",
+		AllVersions:           versions,
+		FailedMinimumVersions: map[int]Version{14: versions[3]},
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeCache(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.AllVersions) != len(versions) {
+		t.Fatalf("decoded %d versions, want %d", len(decoded.AllVersions), len(versions))
+	}
+	for i, v := range versions {
+		if decoded.AllVersions[i] != v {
+			t.Errorf("decoded %+v, want %+v", decoded.AllVersions[i], v)
+		}
+	}
+	if decoded.FailedMinimumVersions[14] != versions[3] {
+		t.Errorf("decoded failed minimum version %+v, want %+v", decoded.FailedMinimumVersions[14], versions[3])
+	}
+}